@@ -0,0 +1,116 @@
+package connmgr
+
+import (
+	"net"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/libp2p/go-libp2p-core/control"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// FilterGater is a ConnectionGater allowing or denying connections based on
+// the IP address of the remote multiaddr. See NewFilterGater.
+type FilterGater struct {
+	allow, deny []*net.IPNet
+	allowNonIP  bool
+}
+
+var _ ConnectionGater = (*FilterGater)(nil)
+
+// FilterGaterOption is an option for NewFilterGater.
+type FilterGaterOption func(*FilterGater)
+
+// AllowNonIP sets whether a FilterGater allows dialing and accepting
+// connections on multiaddrs that don't contain an IP address (e.g.
+// /dns4/... addresses). It defaults to true.
+func AllowNonIP(allow bool) FilterGaterOption {
+	return func(g *FilterGater) {
+		g.allowNonIP = allow
+	}
+}
+
+// NewFilterGater returns a ConnectionGater which gates address dials and
+// accepted connections by the IP address of the remote multiaddr:
+//
+//  * Addresses within one of the deny subnets are rejected, even if they're
+//    also within an allow subnet.
+//  * Otherwise, if allow is empty, addresses are allowed; if it isn't, only
+//    addresses within one of the allow subnets are.
+//
+// Multiaddrs without an IP address are allowed, unless the AllowNonIP(false)
+// option is passed. Peer dials and upgraded connections aren't gated.
+func NewFilterGater(allow, deny []*net.IPNet, opts ...FilterGaterOption) *FilterGater {
+	g := &FilterGater{
+		allow:      allow,
+		deny:       deny,
+		allowNonIP: true,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+func (g *FilterGater) allowed(addr ma.Multiaddr) bool {
+	ip := multiaddrIP(addr)
+	if ip == nil {
+		return g.allowNonIP
+	}
+	for _, n := range g.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(g.allow) == 0 {
+		return true
+	}
+	for _, n := range g.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// multiaddrIP returns the first IP address of addr, or nil if there's none.
+func multiaddrIP(addr ma.Multiaddr) net.IP {
+	var ip net.IP
+	ma.ForEach(addr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_IP4, ma.P_IP6:
+			ip = net.IP(c.RawValue())
+			return false
+		}
+		return true
+	})
+	return ip
+}
+
+// InterceptPeerDial allows all peer dials.
+func (g *FilterGater) InterceptPeerDial(p peer.ID) bool {
+	return true
+}
+
+// InterceptAddrDial allows dialing addr if its IP address is allowed.
+func (g *FilterGater) InterceptAddrDial(p peer.ID, addr ma.Multiaddr) bool {
+	return g.allowed(addr)
+}
+
+// InterceptAccept allows an inbound connection if the IP address of its
+// remote multiaddr is allowed.
+func (g *FilterGater) InterceptAccept(addrs network.ConnMultiaddrs) bool {
+	return g.allowed(addrs.RemoteMultiaddr())
+}
+
+// InterceptSecured allows all secured connections, as their addresses were
+// already gated by InterceptAddrDial or InterceptAccept.
+func (g *FilterGater) InterceptSecured(dir network.Direction, p peer.ID, addrs network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptUpgraded allows all upgraded connections.
+func (g *FilterGater) InterceptUpgraded(c network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
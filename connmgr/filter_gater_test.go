@@ -0,0 +1,63 @@
+package connmgr
+
+import (
+	"net"
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+type testConnAddrs struct {
+	remote ma.Multiaddr
+}
+
+func (c testConnAddrs) LocalMultiaddr() ma.Multiaddr  { return nil }
+func (c testConnAddrs) RemoteMultiaddr() ma.Multiaddr { return c.remote }
+
+func TestFilterGater(t *testing.T) {
+	g := NewFilterGater(
+		[]*net.IPNet{mustParseCIDR(t, "10.0.0.0/8"), mustParseCIDR(t, "2001:db8::/32")},
+		[]*net.IPNet{mustParseCIDR(t, "10.1.0.0/16"), mustParseCIDR(t, "2001:db8:bad::/48")},
+	)
+
+	for addr, expected := range map[string]bool{
+		"/ip4/10.2.3.4/tcp/1":             true,
+		"/ip4/10.1.2.3/tcp/1":             false, // deny takes precedence
+		"/ip4/192.168.1.1/tcp/1":          false, // not allowed
+		"/ip6/2001:db8:1::1/tcp/1":        true,
+		"/ip6/2001:db8:bad::1/udp/1/quic": false,
+		"/ip6/2001:db9::1/tcp/1":          false,
+		"/dns4/example.com/tcp/1":         true, // non-IP
+		"/ip4/10.2.3.4/tcp/1/p2p-circuit": true,
+		"/ip6/::ffff:10.2.3.4/tcp/1":      true, // IPv4-mapped
+	} {
+		maddr := ma.StringCast(addr)
+		if allowed := g.InterceptAddrDial("peer", maddr); allowed != expected {
+			t.Errorf("expected dialing %s to be allowed=%t, got %t", addr, expected, allowed)
+		}
+		if allowed := g.InterceptAccept(testConnAddrs{maddr}); allowed != expected {
+			t.Errorf("expected accepting %s to be allowed=%t, got %t", addr, expected, allowed)
+		}
+	}
+
+	// Without an allow list, everything that isn't denied is allowed.
+	g = NewFilterGater(nil, []*net.IPNet{mustParseCIDR(t, "10.0.0.0/8")}, AllowNonIP(false))
+	if !g.InterceptAddrDial("peer", ma.StringCast("/ip4/1.2.3.4/tcp/1")) {
+		t.Error("expected addresses outside the deny list to be allowed")
+	}
+	if g.InterceptAddrDial("peer", ma.StringCast("/ip4/10.0.0.1/tcp/1")) {
+		t.Error("expected addresses in the deny list to be denied")
+	}
+	if g.InterceptAddrDial("peer", ma.StringCast("/dns4/example.com/tcp/1")) {
+		t.Error("expected non-IP addresses to be denied")
+	}
+}
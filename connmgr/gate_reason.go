@@ -0,0 +1,104 @@
+package connmgr
+
+import (
+	"fmt"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// GateReasonCode classifies why a ConnectionGater rejected a connection. It
+// is meant to be used e.g. as a metrics label.
+type GateReasonCode int
+
+const (
+	// GateReasonUnknown is used when the gater didn't give a reason, e.g.
+	// because it doesn't implement ConnectionGaterWithReasons.
+	GateReasonUnknown GateReasonCode = iota
+	// GateReasonBlocklist means that the peer or address is blocked.
+	GateReasonBlocklist
+	// GateReasonResourceLimit means that accepting the connection would
+	// exceed a resource limit.
+	GateReasonResourceLimit
+	// GateReasonPolicy means that the connection was rejected by a local
+	// policy other than a blocklist.
+	GateReasonPolicy
+)
+
+func (c GateReasonCode) String() string {
+	switch c {
+	case GateReasonUnknown:
+		return "unknown"
+	case GateReasonBlocklist:
+		return "blocklist"
+	case GateReasonResourceLimit:
+		return "resource_limit"
+	case GateReasonPolicy:
+		return "policy"
+	default:
+		return fmt.Sprintf("unrecognized(%d)", int(c))
+	}
+}
+
+// GateReason is a structured explanation of why a ConnectionGater rejected
+// a connection.
+type GateReason struct {
+	Code GateReasonCode
+	// Message is an optional, human readable description.
+	Message string
+}
+
+func (r GateReason) String() string {
+	if r.Message == "" {
+		return r.Code.String()
+	}
+	return r.Code.String() + ": " + r.Message
+}
+
+// ConnectionGaterWithReasons is an optional interface that can be implemented
+// by ConnectionGaters to explain their decisions. Each method must return the
+// same decision as its boolean counterpart in ConnectionGater; the reason is
+// only meaningful when the connection is rejected.
+//
+// Callers should use the InterceptPeerDialReason, InterceptAddrDialReason and
+// InterceptSecuredReason functions, which fall back to the ConnectionGater
+// methods for gaters that don't implement this interface.
+type ConnectionGaterWithReasons interface {
+	ConnectionGater
+
+	InterceptPeerDialReason(p peer.ID) (allow bool, reason GateReason)
+	InterceptAddrDialReason(p peer.ID, addr ma.Multiaddr) (allow bool, reason GateReason)
+	InterceptSecuredReason(dir network.Direction, p peer.ID, addrs network.ConnMultiaddrs) (allow bool, reason GateReason)
+}
+
+// InterceptPeerDialReason calls InterceptPeerDialReason on g if it implements
+// ConnectionGaterWithReasons, and InterceptPeerDial otherwise, in which case
+// rejections have the GateReasonUnknown code.
+func InterceptPeerDialReason(g ConnectionGater, p peer.ID) (allow bool, reason GateReason) {
+	if rg, ok := g.(ConnectionGaterWithReasons); ok {
+		return rg.InterceptPeerDialReason(p)
+	}
+	return g.InterceptPeerDial(p), GateReason{}
+}
+
+// InterceptAddrDialReason calls InterceptAddrDialReason on g if it
+// implements ConnectionGaterWithReasons, and InterceptAddrDial otherwise, in
+// which case rejections have the GateReasonUnknown code.
+func InterceptAddrDialReason(g ConnectionGater, p peer.ID, addr ma.Multiaddr) (allow bool, reason GateReason) {
+	if rg, ok := g.(ConnectionGaterWithReasons); ok {
+		return rg.InterceptAddrDialReason(p, addr)
+	}
+	return g.InterceptAddrDial(p, addr), GateReason{}
+}
+
+// InterceptSecuredReason calls InterceptSecuredReason on g if it implements
+// ConnectionGaterWithReasons, and InterceptSecured otherwise, in which case
+// rejections have the GateReasonUnknown code.
+func InterceptSecuredReason(g ConnectionGater, dir network.Direction, p peer.ID, addrs network.ConnMultiaddrs) (allow bool, reason GateReason) {
+	if rg, ok := g.(ConnectionGaterWithReasons); ok {
+		return rg.InterceptSecuredReason(dir, p, addrs)
+	}
+	return g.InterceptSecured(dir, p, addrs), GateReason{}
+}
@@ -0,0 +1,61 @@
+package connmgr
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/libp2p/go-libp2p-core/control"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+type denyAllGater struct{}
+
+func (denyAllGater) InterceptPeerDial(peer.ID) bool               { return false }
+func (denyAllGater) InterceptAddrDial(peer.ID, ma.Multiaddr) bool { return false }
+func (denyAllGater) InterceptAccept(network.ConnMultiaddrs) bool  { return false }
+func (denyAllGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return false, 0
+}
+func (denyAllGater) InterceptSecured(network.Direction, peer.ID, network.ConnMultiaddrs) bool {
+	return false
+}
+
+type blocklistGater struct{ denyAllGater }
+
+var blocked = GateReason{Code: GateReasonBlocklist, Message: "peer is blocked"}
+
+func (blocklistGater) InterceptPeerDialReason(peer.ID) (bool, GateReason) { return false, blocked }
+func (blocklistGater) InterceptAddrDialReason(peer.ID, ma.Multiaddr) (bool, GateReason) {
+	return false, blocked
+}
+func (blocklistGater) InterceptSecuredReason(network.Direction, peer.ID, network.ConnMultiaddrs) (bool, GateReason) {
+	return false, blocked
+}
+
+func TestGateReason(t *testing.T) {
+	addr := ma.StringCast("/ip4/1.2.3.4/tcp/1234")
+
+	allow, reason := InterceptAddrDialReason(denyAllGater{}, "", addr)
+	if allow || reason.Code != GateReasonUnknown {
+		t.Fatalf("expected a rejection with an unknown reason, got %t, %s", allow, reason)
+	}
+
+	allow, reason = InterceptPeerDialReason(blocklistGater{}, "")
+	if allow || reason != blocked {
+		t.Fatalf("expected a rejection with reason %q, got %t, %q", blocked, allow, reason)
+	}
+	allow, reason = InterceptAddrDialReason(blocklistGater{}, "", addr)
+	if allow || reason != blocked {
+		t.Fatalf("expected a rejection with reason %q, got %t, %q", blocked, allow, reason)
+	}
+	allow, reason = InterceptSecuredReason(blocklistGater{}, network.DirInbound, "", nil)
+	if allow || reason != blocked {
+		t.Fatalf("expected a rejection with reason %q, got %t, %q", blocked, allow, reason)
+	}
+
+	if s := blocked.String(); s != "blocklist: peer is blocked" {
+		t.Fatalf("unexpected reason string %q", s)
+	}
+}
@@ -49,7 +49,8 @@ type ConnManager interface {
 	GetTagInfo(p peer.ID) *TagInfo
 
 	// TrimOpenConns terminates open connections based on an implementation-defined
-	// heuristic.
+	// heuristic. Implementations with access to an event bus should emit an
+	// event.EvtConnManagerTrim whenever a trim closes connections.
 	TrimOpenConns(ctx context.Context)
 
 	// Notifee returns an implementation that can be called back to inform of
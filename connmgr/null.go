@@ -11,6 +11,7 @@ import (
 type NullConnMgr struct{}
 
 var _ ConnManager = (*NullConnMgr)(nil)
+var _ ProtectionInspector = (*NullConnMgr)(nil)
 
 func (NullConnMgr) TagPeer(peer.ID, string, int)             {}
 func (NullConnMgr) UntagPeer(peer.ID, string)                {}
@@ -21,4 +22,6 @@ func (NullConnMgr) Notifee() network.Notifiee                { return network.Gl
 func (NullConnMgr) Protect(peer.ID, string)                  {}
 func (NullConnMgr) Unprotect(peer.ID, string) bool           { return false }
 func (NullConnMgr) IsProtected(peer.ID, string) bool         { return false }
+func (NullConnMgr) ProtectedPeers() []peer.ID                { return nil }
+func (NullConnMgr) ProtectTags(peer.ID) []string             { return nil }
 func (NullConnMgr) Close() error                             { return nil }
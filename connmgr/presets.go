@@ -65,3 +65,28 @@ func BumpOverwrite() BumpFn {
 		return delta
 	}
 }
+
+// DecayWithFloor wraps a DecayFn so that the value of the tag never decays
+// below floor. Once the floor is reached, the tag is retained with the floor
+// value instead of being erased.
+func DecayWithFloor(decay DecayFn, floor int) DecayFn {
+	return func(value DecayingValue) (after int, rm bool) {
+		after, _ = decay(value)
+		if after < floor {
+			after = floor
+		}
+		return after, false
+	}
+}
+
+// BumpWithCeiling wraps a BumpFn so that the value of the tag never exceeds
+// max, no matter how many times it's bumped.
+func BumpWithCeiling(bump BumpFn, max int) BumpFn {
+	return func(value DecayingValue, delta int) (after int) {
+		after = bump(value, delta)
+		if after > max {
+			after = max
+		}
+		return after
+	}
+}
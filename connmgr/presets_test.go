@@ -0,0 +1,48 @@
+package connmgr
+
+import (
+	"testing"
+)
+
+func TestBumpWithCeiling(t *testing.T) {
+	bump := BumpWithCeiling(BumpSumUnbounded(), 100)
+
+	var value DecayingValue
+	for i := 0; i < 10; i++ {
+		value.Value = bump(value, 30)
+		if value.Value > 100 {
+			t.Fatalf("value %d exceeds the ceiling", value.Value)
+		}
+	}
+	if value.Value != 100 {
+		t.Fatalf("expected value to be capped at 100, got %d", value.Value)
+	}
+
+	// Bumping down is unaffected.
+	if v := bump(value, -30); v != 70 {
+		t.Fatalf("expected 70, got %d", v)
+	}
+}
+
+func TestDecayWithFloor(t *testing.T) {
+	decay := DecayWithFloor(DecayFixed(10), 25)
+
+	value := DecayingValue{Value: 60}
+	expected := []int{50, 40, 30, 25, 25, 25}
+	for i, exp := range expected {
+		v, rm := decay(value)
+		if rm {
+			t.Fatalf("interval %d: tag should never be removed", i)
+		}
+		if v != exp {
+			t.Fatalf("interval %d: expected %d, got %d", i, exp, v)
+		}
+		value.Value = v
+	}
+
+	// DecayFixed alone would remove the tag when reaching zero.
+	decay = DecayWithFloor(DecayFixed(10), 0)
+	if v, rm := decay(DecayingValue{Value: 5}); rm || v != 0 {
+		t.Fatalf("expected the tag to be kept at 0, got %d (rm: %t)", v, rm)
+	}
+}
@@ -0,0 +1,25 @@
+package connmgr
+
+import (
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ProtectionInspector is implemented by connection managers that expose
+// their protection state, e.g. to diagnose why a peer is never trimmed. Its
+// methods must be safe to call concurrently with Protect and Unprotect.
+type ProtectionInspector interface {
+	// ProtectedPeers returns the peers that are currently protected under at
+	// least one tag.
+	ProtectedPeers() []peer.ID
+
+	// ProtectTags returns the tags under which the given peer is protected,
+	// or nil if it isn't protected.
+	ProtectTags(p peer.ID) []string
+}
+
+// SupportsProtectionInspection evaluates if the provided ConnManager exposes
+// its protection state, and if so, it returns the ProtectionInspector object.
+func SupportsProtectionInspection(mgr ConnManager) (ProtectionInspector, bool) {
+	pi, ok := mgr.(ProtectionInspector)
+	return pi, ok
+}
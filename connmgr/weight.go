@@ -0,0 +1,38 @@
+package connmgr
+
+import (
+	"github.com/libp2p/go-libp2p-core/network"
+)
+
+// WeightFunc computes a dynamic weight for a connection, e.g. based on how
+// much data is being transferred over it. Higher weights make the connection
+// less likely to be trimmed.
+type WeightFunc func(c network.Conn) int
+
+// WeightedTrimmer is implemented by connection managers that can consult a
+// dynamic weight function when trimming connections.
+//
+// When trimming, the precedence is as follows:
+//
+//  * Connections to protected peers (see ConnManager.Protect) are never
+//    trimmed, regardless of their weight.
+//  * Other connections are ranked by the sum of their peer's static tag
+//    values (see TagInfo.Value) and of the weight returned by the WeightFunc
+//    for the connection. Connections with the lowest total are trimmed
+//    first.
+//
+// The WeightFunc is called during trims, from the trimming goroutine, and
+// must therefore be fast and must not call back into the connection manager.
+type WeightedTrimmer interface {
+	// SetWeightFunc sets the weight function consulted when trimming,
+	// replacing any previously set function. A nil function disables
+	// dynamic weights.
+	SetWeightFunc(WeightFunc)
+}
+
+// SupportsWeightFunc evaluates if the provided ConnManager supports dynamic
+// weight functions, and if so, it returns the WeightedTrimmer object.
+func SupportsWeightFunc(mgr ConnManager) (WeightedTrimmer, bool) {
+	w, ok := mgr.(WeightedTrimmer)
+	return w, ok
+}
@@ -0,0 +1,87 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrBatchLengthMismatch is returned by BatchVerify when the keys, messages
+// and signatures passed to it don't have the same length.
+var ErrBatchLengthMismatch = errors.New("batch verify: keys, messages and signatures must have the same length")
+
+// minParallelBatch is the batch size below which it isn't worth spreading
+// ed25519 verification over multiple goroutines.
+const minParallelBatch = 64
+
+// BatchVerify verifies a batch of signatures, returning the validity of each
+// signature positionally: the i-th result reports whether sigs[i] is a valid
+// signature of msgs[i] by pubs[i].
+//
+// Verification never stops at the first invalid signature. If verifying a
+// single signature fails with an error (e.g. a malformed signature), that
+// signature is simply reported as invalid.
+//
+// When every key in the batch is an Ed25519 key, the batch is verified on a
+// fast path that bypasses the PubKey interface and spreads the work across
+// the available CPUs. Otherwise, each signature is verified with the
+// corresponding key's Verify method.
+func BatchVerify(pubs []PubKey, msgs [][]byte, sigs [][]byte) ([]bool, error) {
+	if len(pubs) != len(msgs) || len(pubs) != len(sigs) {
+		return nil, ErrBatchLengthMismatch
+	}
+
+	results := make([]bool, len(pubs))
+
+	edKeys := make([]ed25519.PublicKey, len(pubs))
+	for i, pub := range pubs {
+		edk, ok := pub.(*Ed25519PublicKey)
+		if !ok {
+			edKeys = nil
+			break
+		}
+		edKeys[i] = edk.k
+	}
+
+	if edKeys != nil {
+		batchVerifyEd25519(edKeys, msgs, sigs, results)
+		return results, nil
+	}
+
+	for i, pub := range pubs {
+		if pub == nil {
+			continue
+		}
+		ok, err := pub.Verify(msgs[i], sigs[i])
+		results[i] = ok && err == nil
+	}
+	return results, nil
+}
+
+func batchVerifyEd25519(keys []ed25519.PublicKey, msgs [][]byte, sigs [][]byte, results []bool) {
+	workers := runtime.GOMAXPROCS(0)
+	if len(keys) < minParallelBatch || workers < 2 {
+		for i := range keys {
+			results[i] = ed25519.Verify(keys[i], msgs[i], sigs[i])
+		}
+		return
+	}
+
+	chunk := (len(keys) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(keys); start += chunk {
+		end := start + chunk
+		if end > len(keys) {
+			end = len(keys)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				results[i] = ed25519.Verify(keys[i], msgs[i], sigs[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
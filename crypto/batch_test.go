@@ -0,0 +1,102 @@
+package crypto_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func makeBatch(t *testing.T, typs []int) ([]PubKey, [][]byte, [][]byte) {
+	t.Helper()
+
+	pubs := make([]PubKey, len(typs))
+	msgs := make([][]byte, len(typs))
+	sigs := make([][]byte, len(typs))
+	for i, typ := range typs {
+		bits := 512
+		if typ == RSA {
+			bits = 2048
+		}
+		priv, pub, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+		sigs[i], err = priv.Sign(msgs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs[i] = pub
+	}
+	return pubs, msgs, sigs
+}
+
+func checkBatch(t *testing.T, pubs []PubKey, msgs, sigs [][]byte, invalid map[int]bool) {
+	t.Helper()
+
+	res, err := BatchVerify(pubs, msgs, sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(pubs) {
+		t.Fatalf("expected %d results, got %d", len(pubs), len(res))
+	}
+	for i, ok := range res {
+		if ok == invalid[i] {
+			t.Errorf("signature %d: expected valid=%t, got %t", i, !invalid[i], ok)
+		}
+	}
+}
+
+func TestBatchVerifyEd25519(t *testing.T) {
+	// Large enough to exercise the parallel path.
+	typs := make([]int, 100)
+	for i := range typs {
+		typs[i] = Ed25519
+	}
+	pubs, msgs, sigs := makeBatch(t, typs)
+
+	checkBatch(t, pubs, msgs, sigs, nil)
+
+	// Corrupt a few signatures; the others must still verify.
+	invalid := map[int]bool{0: true, 42: true, 99: true}
+	for i := range invalid {
+		sigs[i] = append([]byte{}, sigs[i]...)
+		sigs[i][0] ^= 0xff
+	}
+	checkBatch(t, pubs, msgs, sigs, invalid)
+}
+
+func TestBatchVerifyMixed(t *testing.T) {
+	pubs, msgs, sigs := makeBatch(t, []int{Ed25519, ECDSA, Secp256k1, RSA, Ed25519, ECDSA})
+
+	checkBatch(t, pubs, msgs, sigs, nil)
+
+	// A garbage ECDSA signature makes Verify error; it must be reported as
+	// invalid without affecting the rest of the batch.
+	sigs[1] = []byte("not a signature")
+	// Swap the messages of two entries.
+	msgs[3], msgs[4] = msgs[4], msgs[3]
+	checkBatch(t, pubs, msgs, sigs, map[int]bool{1: true, 3: true, 4: true})
+}
+
+func TestBatchVerifyLengthMismatch(t *testing.T) {
+	pubs, msgs, sigs := makeBatch(t, []int{Ed25519, Ed25519})
+
+	if _, err := BatchVerify(pubs, msgs[:1], sigs); err != ErrBatchLengthMismatch {
+		t.Fatalf("expected ErrBatchLengthMismatch, got %v", err)
+	}
+	if _, err := BatchVerify(pubs, msgs, sigs[:1]); err != ErrBatchLengthMismatch {
+		t.Fatalf("expected ErrBatchLengthMismatch, got %v", err)
+	}
+
+	res, err := BatchVerify(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 0 {
+		t.Fatal("expected no results for an empty batch")
+	}
+}
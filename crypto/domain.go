@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"errors"
+
+	"github.com/multiformats/go-varint"
+)
+
+// ErrEmptyDomain is returned when signing or verifying with an empty domain.
+var ErrEmptyDomain = errors.New("signature domain must not be empty")
+
+// SignWithDomain signs data with the given private key, after binding it to
+// a domain string. This prevents a signature produced for one purpose from
+// being replayed in another context that uses the same key.
+//
+// The domain is prefixed with its length as an unsigned varint before being
+// prepended to the data, so distinct (domain, data) pairs never produce the
+// same signed message. The data itself isn't length-prefixed, so these
+// signatures aren't compatible with record.Envelope's, which length-prefix
+// each signed field.
+func SignWithDomain(priv PrivKey, domain string, data []byte) ([]byte, error) {
+	if domain == "" {
+		return nil, ErrEmptyDomain
+	}
+	return priv.Sign(domainSeparated(domain, data))
+}
+
+// VerifyWithDomain verifies a signature produced by SignWithDomain with the
+// same domain.
+func VerifyWithDomain(pub PubKey, domain string, data []byte, sig []byte) (bool, error) {
+	if domain == "" {
+		return false, ErrEmptyDomain
+	}
+	return pub.Verify(domainSeparated(domain, data), sig)
+}
+
+func domainSeparated(domain string, data []byte) []byte {
+	prefix := varint.ToUvarint(uint64(len(domain)))
+	b := make([]byte, 0, len(prefix)+len(domain)+len(data))
+	b = append(b, prefix...)
+	b = append(b, domain...)
+	return append(b, data...)
+}
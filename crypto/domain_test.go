@@ -0,0 +1,46 @@
+package crypto_test
+
+import (
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestSignWithDomain(t *testing.T) {
+	priv, pub, err := test.RandTestKeyPair(Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := SignWithDomain(priv, "ab", []byte("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := VerifyWithDomain(pub, "ab", []byte("c"), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("signature didn't verify")
+	}
+
+	// Moving bytes between the domain and the data must invalidate the
+	// signature.
+	ok, _ = VerifyWithDomain(pub, "a", []byte("bc"), sig)
+	if ok {
+		t.Fatal("signature verified with a different domain/data split")
+	}
+	// As must verifying without the domain.
+	ok, _ = pub.Verify([]byte("abc"), sig)
+	if ok {
+		t.Fatal("domain-separated signature verified as a plain signature")
+	}
+
+	if _, err := SignWithDomain(priv, "", []byte("c")); err != ErrEmptyDomain {
+		t.Fatalf("expected ErrEmptyDomain, got %v", err)
+	}
+	if _, err := VerifyWithDomain(pub, "", []byte("c"), sig); err != ErrEmptyDomain {
+		t.Fatalf("expected ErrEmptyDomain, got %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package crypto
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/x509"
 	"encoding/asn1"
 	"errors"
@@ -117,19 +116,41 @@ func (ePriv *ECDSAPrivateKey) Raw() ([]byte, error) {
 	return x509.MarshalECPrivateKey(ePriv.priv)
 }
 
-// Equals compares two private keys
+// Equals compares two private keys in constant time
 func (ePriv *ECDSAPrivateKey) Equals(o Key) bool {
-	return basicEquals(ePriv, o)
+	oPriv, ok := o.(*ECDSAPrivateKey)
+	if !ok {
+		return basicEquals(ePriv, o)
+	}
+
+	// The curve is public information.
+	params := ePriv.priv.Curve.Params()
+	if params.Name != oPriv.priv.Curve.Params().Name {
+		return false
+	}
+	return secretIntsEqual(ePriv.priv.D, oPriv.priv.D, (params.BitSize+7)/8)
 }
 
-// Sign returns the signature of the input data
+// Sign returns the signature of the input data. Signatures are
+// deterministic: the nonce is derived from the key and the data as specified
+// in RFC 6979.
 func (ePriv *ECDSAPrivateKey) Sign(data []byte) ([]byte, error) {
 	hash := sha256.Sum256(data)
-	r, s, err := ecdsa.Sign(rand.Reader, ePriv.priv, hash[:])
+	return ePriv.signHash(hash[:])
+}
+
+// SignStream returns the signature of the data read from r, hashing it
+// incrementally. The signature is the same as the one Sign would return.
+func (ePriv *ECDSAPrivateKey) SignStream(r io.Reader) ([]byte, error) {
+	hash, err := hashStream(r)
 	if err != nil {
 		return nil, err
 	}
+	return ePriv.signHash(hash)
+}
 
+func (ePriv *ECDSAPrivateKey) signHash(hash []byte) ([]byte, error) {
+	r, s := signRFC6979(ePriv.priv, hash)
 	return asn1.Marshal(ECDSASig{
 		R: r,
 		S: s,
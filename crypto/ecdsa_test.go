@@ -1,7 +1,12 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
 	"testing"
 )
 
@@ -94,3 +99,102 @@ func TestECDSAMarshalLoop(t *testing.T) {
 	}
 
 }
+
+func TestECDSADeterministicSignature(t *testing.T) {
+	for _, c := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		priv, pub, err := GenerateECDSAKeyPairWithCurve(c, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := []byte("hello! and welcome to some awesome crypto primitives")
+		sig1, err := priv.Sign(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := priv.Sign(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig1, sig2) {
+			t.Fatalf("%s: expected signing the same data twice to yield the same signature", c.Params().Name)
+		}
+		if ok, err := pub.Verify(data, sig1); err != nil || !ok {
+			t.Fatalf("%s: signature didn't match", c.Params().Name)
+		}
+	}
+}
+
+// TestECDSARFC6979Vector checks the P-256, SHA-256 test vector of RFC 6979,
+// appendix A.2.5.
+func TestECDSARFC6979Vector(t *testing.T) {
+	hexInt := func(s string) *big.Int {
+		i, ok := new(big.Int).SetString(s, 16)
+		if !ok {
+			t.Fatalf("invalid hex integer %s", s)
+		}
+		return i
+	}
+	key := &ecdsa.PrivateKey{D: hexInt("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")}
+	key.Curve = elliptic.P256()
+	key.X, key.Y = key.Curve.ScalarBaseMult(key.D.Bytes())
+
+	priv, _, err := ECDSAKeyPairFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := priv.Sign([]byte("sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ECDSASig
+	if _, err := asn1.Unmarshal(sig, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	r := hexInt("EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716")
+	s := hexInt("F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8")
+	if decoded.R.Cmp(r) != 0 || decoded.S.Cmp(s) != 0 {
+		t.Fatalf("expected (r, s) = (%X, %X), got (%X, %X)", r, s, decoded.R, decoded.S)
+	}
+}
+
+func TestScalarFieldMatchesBigInt(t *testing.T) {
+	for _, c := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		n := c.Params().N
+		f := newScalarField(n)
+		toBig := func(x scalar) *big.Int {
+			return new(big.Int).SetBytes(f.toBytes(x))
+		}
+		for i := 0; i < 50; i++ {
+			a, err := rand.Int(rand.Reader, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := rand.Int(rand.Reader, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			as := f.fromBytes(a.Bytes())
+			bs := f.fromBytes(b.Bytes())
+
+			sum := new(big.Int).Add(a, b)
+			sum.Mod(sum, n)
+			if got := toBig(f.add(as, bs)); got.Cmp(sum) != 0 {
+				t.Fatalf("%s: %X + %X: expected %X, got %X", c.Params().Name, a, b, sum, got)
+			}
+
+			prod := new(big.Int).Mul(a, b)
+			prod.Mod(prod, n)
+			if got := toBig(f.fromMont(f.mul(f.toMont(as), f.toMont(bs)))); got.Cmp(prod) != 0 {
+				t.Fatalf("%s: %X * %X: expected %X, got %X", c.Params().Name, a, b, prod, got)
+			}
+
+			if a.Sign() == 0 {
+				continue
+			}
+			inv := new(big.Int).ModInverse(a, n)
+			if got := toBig(f.fromMont(f.inv(f.toMont(as)))); got.Cmp(inv) != 0 {
+				t.Fatalf("%s: 1 / %X: expected %X, got %X", c.Params().Name, a, inv, got)
+			}
+		}
+	}
+}
@@ -37,6 +37,16 @@ func GenerateEd25519Key(src io.Reader) (PrivKey, PubKey, error) {
 		nil
 }
 
+// GenerateEd25519KeyFromSeed deterministically derives an ed25519 key pair
+// from a 32 byte seed, as defined in RFC 8032. The same seed always yields
+// the same key pair (and thus the same peer ID), which is useful for test
+// fixtures. The seed must be kept secret and should be uniformly random.
+func GenerateEd25519KeyFromSeed(seed [ed25519.SeedSize]byte) (PrivKey, PubKey, error) {
+	priv := ed25519.NewKeyFromSeed(seed[:])
+	sk := &Ed25519PrivateKey{k: priv}
+	return sk, sk.GetPublic(), nil
+}
+
 // Type of the private key (Ed25519).
 func (k *Ed25519PrivateKey) Type() pb.KeyType {
 	return pb.KeyType_Ed25519
@@ -59,11 +69,17 @@ func (k *Ed25519PrivateKey) Raw() ([]byte, error) {
 	return buf, nil
 }
 
+// Seed returns the 32 byte seed the private key was derived from. It's the
+// inverse of GenerateEd25519KeyFromSeed.
+func (k *Ed25519PrivateKey) Seed() []byte {
+	return k.k.Seed()
+}
+
 func (k *Ed25519PrivateKey) pubKeyBytes() []byte {
 	return k.k[ed25519.PrivateKeySize-ed25519.PublicKeySize:]
 }
 
-// Equals compares two ed25519 private keys.
+// Equals compares two ed25519 private keys in constant time.
 func (k *Ed25519PrivateKey) Equals(o Key) bool {
 	edk, ok := o.(*Ed25519PrivateKey)
 	if !ok {
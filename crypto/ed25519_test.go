@@ -1,8 +1,10 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/hex"
 	"testing"
 
 	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
@@ -217,3 +219,45 @@ func TestUnmarshalErrors(t *testing.T) {
 		})
 	})
 }
+
+func TestGenerateFromSeed(t *testing.T) {
+	// Test vector 1 from RFC 8032, section 7.1.
+	var seed [32]byte
+	copy(seed[:], mustHex(t, "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+	expectedPub := mustHex(t, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+
+	priv, pub, err := GenerateEd25519KeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubRaw, err := pub.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pubRaw, expectedPub) {
+		t.Fatalf("unexpected public key %x", pubRaw)
+	}
+	if !priv.GetPublic().Equals(pub) {
+		t.Fatal("public keys don't match")
+	}
+	if !bytes.Equal(priv.(*Ed25519PrivateKey).Seed(), seed[:]) {
+		t.Fatal("seed didn't round-trip")
+	}
+
+	priv2, _, err := GenerateEd25519KeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equals(priv2) {
+		t.Fatal("same seed generated different keys")
+	}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,147 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+// Ed448PrivateKey is an ed448 private key.
+type Ed448PrivateKey struct {
+	k ed448.PrivateKey
+}
+
+// Ed448PublicKey is an ed448 public key.
+type Ed448PublicKey struct {
+	k ed448.PublicKey
+}
+
+// GenerateEd448Key generates a new ed448 private and public key pair.
+func GenerateEd448Key(src io.Reader) (PrivKey, PubKey, error) {
+	pub, priv, err := ed448.GenerateKey(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Ed448PrivateKey{
+			k: priv,
+		},
+		&Ed448PublicKey{
+			k: pub,
+		},
+		nil
+}
+
+// Type of the private key (Ed448).
+func (k *Ed448PrivateKey) Type() pb.KeyType {
+	return pb.KeyType_Ed448
+}
+
+// Bytes marshals an ed448 private key to protobuf bytes.
+func (k *Ed448PrivateKey) Bytes() ([]byte, error) {
+	return MarshalPrivateKey(k)
+}
+
+// Raw private key bytes.
+func (k *Ed448PrivateKey) Raw() ([]byte, error) {
+	// Like ed25519, the ed448 private key is the seed followed by the
+	// public key.
+	buf := make([]byte, len(k.k))
+	copy(buf, k.k)
+
+	return buf, nil
+}
+
+func (k *Ed448PrivateKey) pubKeyBytes() []byte {
+	return k.k[ed448.PrivateKeySize-ed448.PublicKeySize:]
+}
+
+// Equals compares two ed448 private keys in constant time.
+func (k *Ed448PrivateKey) Equals(o Key) bool {
+	edk, ok := o.(*Ed448PrivateKey)
+	if !ok {
+		return basicEquals(k, o)
+	}
+
+	return subtle.ConstantTimeCompare(k.k, edk.k) == 1
+}
+
+// GetPublic returns an ed448 public key from a private key.
+func (k *Ed448PrivateKey) GetPublic() PubKey {
+	return &Ed448PublicKey{k: k.pubKeyBytes()}
+}
+
+// Sign returns a signature from an input message.
+//
+// Signatures are produced with an empty context string (pure Ed448).
+func (k *Ed448PrivateKey) Sign(msg []byte) ([]byte, error) {
+	return ed448.Sign(k.k, msg, ""), nil
+}
+
+// Type of the public key (Ed448).
+func (k *Ed448PublicKey) Type() pb.KeyType {
+	return pb.KeyType_Ed448
+}
+
+// Bytes returns a ed448 public key as protobuf bytes.
+func (k *Ed448PublicKey) Bytes() ([]byte, error) {
+	return MarshalPublicKey(k)
+}
+
+// Raw public key bytes.
+func (k *Ed448PublicKey) Raw() ([]byte, error) {
+	return k.k, nil
+}
+
+// Equals compares two ed448 public keys.
+func (k *Ed448PublicKey) Equals(o Key) bool {
+	edk, ok := o.(*Ed448PublicKey)
+	if !ok {
+		return basicEquals(k, o)
+	}
+
+	return bytes.Equal(k.k, edk.k)
+}
+
+// Verify checks a signature agains the input data.
+func (k *Ed448PublicKey) Verify(data []byte, sig []byte) (bool, error) {
+	return ed448.Verify(k.k, data, sig, ""), nil
+}
+
+// UnmarshalEd448PublicKey returns a public key from input bytes.
+func UnmarshalEd448PublicKey(data []byte) (PubKey, error) {
+	if len(data) != ed448.PublicKeySize {
+		return nil, fmt.Errorf("expect ed448 public key data size to be %d", ed448.PublicKeySize)
+	}
+
+	return &Ed448PublicKey{
+		k: ed448.PublicKey(data),
+	}, nil
+}
+
+// UnmarshalEd448PrivateKey returns a private key from input bytes.
+func UnmarshalEd448PrivateKey(data []byte) (PrivKey, error) {
+	if len(data) != ed448.PrivateKeySize {
+		return nil, fmt.Errorf(
+			"expected ed448 data size to be %d, got %d",
+			ed448.PrivateKeySize,
+			len(data),
+		)
+	}
+
+	// Make sure the embedded public key actually matches the seed.
+	derived := ed448.NewKeyFromSeed(data[:ed448.SeedSize])
+	if subtle.ConstantTimeCompare(derived, data) == 0 {
+		return nil, errors.New("ed448 public key does not match private key")
+	}
+
+	return &Ed448PrivateKey{
+		k: derived,
+	}, nil
+}
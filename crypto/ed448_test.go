@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+func TestEd448BasicSignAndVerify(t *testing.T) {
+	priv, pub, err := GenerateEd448Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("hello! and welcome to some awesome crypto primitives")
+
+	sig, err := priv.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := pub.Verify(data, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok {
+		t.Fatal("signature didn't match")
+	}
+
+	// change data
+	data[0] = ^data[0]
+	ok, err = pub.Verify(data, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok {
+		t.Fatal("signature matched and shouldn't")
+	}
+}
+
+func TestEd448MarshalLoop(t *testing.T) {
+	priv, pub, err := GenerateKeyPair(Ed448, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("My child, my sister,\nThink of the rapture\nOf living together there!")
+	signed, err := priv.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privBytes, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privNew, err := UnmarshalPrivateKey(privBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privNew.Type() != pb.KeyType_Ed448 {
+		t.Fatalf("expected key type %s, got %s", pb.KeyType_Ed448, privNew.Type())
+	}
+	if !priv.Equals(privNew) || !privNew.Equals(priv) {
+		t.Fatal("private keys are not equal")
+	}
+
+	pubBytes, err := MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubNew, err := UnmarshalPublicKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equals(pubNew) || !pubNew.Equals(pub) {
+		t.Fatal("public keys are not equal")
+	}
+
+	// A signature made before marshaling must verify after unmarshaling,
+	// and vice versa.
+	ok, err := pubNew.Verify(msg, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("signature didn't match after round-trip")
+	}
+
+	signedNew, err := privNew.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err = pub.Verify(msg, signedNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("signature from unmarshaled key didn't match")
+	}
+}
+
+func TestEd448UnmarshalErrors(t *testing.T) {
+	t.Run("PublicKey", func(t *testing.T) {
+		pbmes := &pb.PublicKey{
+			Type: pb.KeyType_Ed448,
+			Data: []byte{42},
+		}
+
+		data, err := pbmes.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := UnmarshalPublicKey(data); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("PrivateKey", func(t *testing.T) {
+		priv, _, err := GenerateEd448Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := priv.Raw()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Corrupt the embedded public key.
+		data[ed448.PrivateKeySize-1] ^= 0xff
+		pbmes := &pb.PrivateKey{
+			Type: pb.KeyType_Ed448,
+			Data: data,
+		}
+		b, err := pbmes.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := UnmarshalPrivateKey(b); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
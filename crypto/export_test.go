@@ -0,0 +1,4 @@
+package crypto
+
+// UnregisterKeyType exposes unregisterKeyType to the external tests.
+var UnregisterKeyType = unregisterKeyType
@@ -37,6 +37,11 @@ var keyTypes = []testCase{
 			return crypto.GenerateRSAKeyPair(2048, i)
 		},
 	},
+	{
+		keyType:          crypto_pb.KeyType_Ed448,
+		sigDeterministic: true,
+		gen:              crypto.GenerateEd448Key,
+	},
 }
 
 func fname(kt crypto_pb.KeyType, ext string) string {
@@ -0,0 +1,201 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// PubKeyToJWK converts a public key into a JSON Web Key (RFC 7517), suitable
+// for publishing in a JWKS document.
+//
+// Ed25519 and Ed448 keys are exported as "OKP" keys (RFC 8037), ECDSA keys
+// as "EC" keys and RSA keys as "RSA" keys (RFC 7518). All binary fields are
+// base64url encoded without padding.
+func PubKeyToJWK(k PubKey) (map[string]interface{}, error) {
+	if k == nil {
+		return nil, ErrNilPublicKey
+	}
+
+	switch k.Type() {
+	case Ed25519, Ed448:
+		raw, err := k.Raw()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"kty": "OKP",
+			"crv": k.Type().String(),
+			"x":   b64url(raw),
+		}, nil
+	case ECDSA:
+		std, err := PubKeyToStdKey(k)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := std.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, ErrNotECDSAPubKey
+		}
+		params := pub.Curve.Params()
+		size := (params.BitSize + 7) / 8
+		return map[string]interface{}{
+			"kty": "EC",
+			"crv": params.Name,
+			"x":   b64url(padBytes(pub.X.Bytes(), size)),
+			"y":   b64url(padBytes(pub.Y.Bytes(), size)),
+		}, nil
+	case RSA:
+		std, err := PubKeyToStdKey(k)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := std.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("not actually an rsa public key")
+		}
+		return map[string]interface{}{
+			"kty": "RSA",
+			"n":   b64url(pub.N.Bytes()),
+			"e":   b64url(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// PubKeyFromJWK converts a JSON Web Key back into a public key. It is the
+// inverse of PubKeyToJWK.
+//
+// The required fields of each key type are validated, and keys with an
+// unknown "kty" or "crv" are rejected.
+func PubKeyFromJWK(jwk map[string]interface{}) (PubKey, error) {
+	kty, err := jwkString(jwk, "kty")
+	if err != nil {
+		return nil, err
+	}
+
+	switch kty {
+	case "OKP":
+		crv, err := jwkString(jwk, "crv")
+		if err != nil {
+			return nil, err
+		}
+		x, err := jwkBytes(jwk, "x")
+		if err != nil {
+			return nil, err
+		}
+		switch crv {
+		case "Ed25519":
+			return UnmarshalEd25519PublicKey(x)
+		case "Ed448":
+			return UnmarshalEd448PublicKey(x)
+		default:
+			return nil, fmt.Errorf("jwk: unsupported OKP curve %q", crv)
+		}
+	case "EC":
+		crv, err := jwkString(jwk, "crv")
+		if err != nil {
+			return nil, err
+		}
+		var curve elliptic.Curve
+		switch crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk: unsupported EC curve %q", crv)
+		}
+		x, err := jwkBytes(jwk, "x")
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwkBytes(jwk, "y")
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("jwk: EC point is not on the curve")
+		}
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		return UnmarshalECDSAPublicKey(der)
+	case "RSA":
+		n, err := jwkBytes(jwk, "n")
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkBytes(jwk, "e")
+		if err != nil {
+			return nil, err
+		}
+		eInt := new(big.Int).SetBytes(e)
+		if !eInt.IsInt64() || eInt.Int64() > int64(^uint32(0)>>1) || eInt.Int64() < 2 {
+			return nil, errors.New("jwk: invalid RSA exponent")
+		}
+		der, err := x509.MarshalPKIXPublicKey(&rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(eInt.Int64()),
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Go through the regular path so that the key size is checked.
+		return UnmarshalRsaPublicKey(der)
+	default:
+		return nil, fmt.Errorf("jwk: unsupported key type %q", kty)
+	}
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// padBytes left-pads b with zeros up to size bytes.
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
+
+func jwkString(jwk map[string]interface{}, field string) (string, error) {
+	v, ok := jwk[field]
+	if !ok {
+		return "", fmt.Errorf("jwk: missing required field %q", field)
+	}
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("jwk: field %q must be a non-empty string", field)
+	}
+	return s, nil
+}
+
+func jwkBytes(jwk map[string]interface{}, field string) ([]byte, error) {
+	s, err := jwkString(jwk, field)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("jwk: field %q is not valid base64url: %s", field, err)
+	}
+	return b, nil
+}
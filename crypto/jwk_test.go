@@ -0,0 +1,73 @@
+package crypto_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestJWKRoundTrip(t *testing.T) {
+	for _, typ := range []int{Ed25519, ECDSA, RSA, Ed448} {
+		bits := 512
+		if typ == RSA {
+			bits = 2048
+		}
+		_, pk, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(pk.Type().String(), func(t *testing.T) {
+			jwk, err := PubKeyToJWK(pk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Make sure the JWK survives a trip through JSON, as it would
+			// when served from a JWKS endpoint.
+			b, err := json.Marshal(jwk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string]interface{}
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			pk2, err := PubKeyFromJWK(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pk.Equals(pk2) {
+				t.Fatal("public key didn't survive JWK round-trip")
+			}
+		})
+	}
+}
+
+func TestJWKUnsupported(t *testing.T) {
+	_, pk, err := test.RandTestKeyPair(Secp256k1, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PubKeyToJWK(pk); err != ErrBadKeyType {
+		t.Fatalf("expected ErrBadKeyType, got %v", err)
+	}
+
+	for name, jwk := range map[string]map[string]interface{}{
+		"unknown kty":   {"kty": "oct", "k": "AAAA"},
+		"missing kty":   {"x": "AAAA"},
+		"missing x":     {"kty": "OKP", "crv": "Ed25519"},
+		"unknown crv":   {"kty": "EC", "crv": "P-192", "x": "AAAA", "y": "AAAA"},
+		"missing y":     {"kty": "EC", "crv": "P-256", "x": "AAAA"},
+		"off curve":     {"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"},
+		"missing e":     {"kty": "RSA", "n": "AAAA"},
+		"bad base64url": {"kty": "OKP", "crv": "Ed25519", "x": "!!!"},
+	} {
+		if _, err := PubKeyFromJWK(jwk); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -11,10 +11,13 @@ import (
 	"crypto/sha512"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"math/big"
+	"strings"
 
 	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
 
@@ -31,6 +34,8 @@ const (
 	Secp256k1
 	// ECDSA is an enum for the supported ECDSA key type
 	ECDSA
+	// Ed448 is an enum for the supported Ed448 key type
+	Ed448
 )
 
 var (
@@ -42,6 +47,7 @@ var (
 		Ed25519,
 		Secp256k1,
 		ECDSA,
+		Ed448,
 	}
 )
 
@@ -52,19 +58,79 @@ type PubKeyUnmarshaller func(data []byte) (PubKey, error)
 type PrivKeyUnmarshaller func(data []byte) (PrivKey, error)
 
 // PubKeyUnmarshallers is a map of unmarshallers by key type
-var PubKeyUnmarshallers = map[pb.KeyType]PubKeyUnmarshaller{
-	pb.KeyType_RSA:       UnmarshalRsaPublicKey,
-	pb.KeyType_Ed25519:   UnmarshalEd25519PublicKey,
-	pb.KeyType_Secp256k1: UnmarshalSecp256k1PublicKey,
-	pb.KeyType_ECDSA:     UnmarshalECDSAPublicKey,
-}
+var PubKeyUnmarshallers = map[pb.KeyType]PubKeyUnmarshaller{}
 
 // PrivKeyUnmarshallers is a map of unmarshallers by key type
-var PrivKeyUnmarshallers = map[pb.KeyType]PrivKeyUnmarshaller{
-	pb.KeyType_RSA:       UnmarshalRsaPrivateKey,
-	pb.KeyType_Ed25519:   UnmarshalEd25519PrivateKey,
-	pb.KeyType_Secp256k1: UnmarshalSecp256k1PrivateKey,
-	pb.KeyType_ECDSA:     UnmarshalECDSAPrivateKey,
+var PrivKeyUnmarshallers = map[pb.KeyType]PrivKeyUnmarshaller{}
+
+func init() {
+	builtin := []struct {
+		kt   pb.KeyType
+		priv PrivKeyUnmarshaller
+		pub  PubKeyUnmarshaller
+	}{
+		{pb.KeyType_RSA, UnmarshalRsaPrivateKey, UnmarshalRsaPublicKey},
+		{pb.KeyType_Ed25519, UnmarshalEd25519PrivateKey, UnmarshalEd25519PublicKey},
+		{pb.KeyType_Secp256k1, UnmarshalSecp256k1PrivateKey, UnmarshalSecp256k1PublicKey},
+		{pb.KeyType_ECDSA, UnmarshalECDSAPrivateKey, UnmarshalECDSAPublicKey},
+		{pb.KeyType_Ed448, UnmarshalEd448PrivateKey, UnmarshalEd448PublicKey},
+	}
+	for _, b := range builtin {
+		if err := RegisterKeyType(b.kt, b.priv, b.pub); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterKeyType registers the unmarshallers for a key type, so that keys of
+// that type can be decoded by UnmarshalPrivateKey and UnmarshalPublicKey.
+// This allows external packages to plug in key types that aren't supported
+// by this package.
+//
+// Either unmarshaller may be nil (e.g. for keys that can't be exported), but
+// not both. Registering an already registered key type, including any of the
+// built-in ones, returns an error.
+//
+// RegisterKeyType is not safe to call concurrently with unmarshalling keys;
+// it's meant to be called from an init function.
+func RegisterKeyType(kt pb.KeyType, unmarshalPriv PrivKeyUnmarshaller, unmarshalPub PubKeyUnmarshaller) error {
+	if unmarshalPriv == nil && unmarshalPub == nil {
+		return fmt.Errorf("no unmarshallers given for key type %d", kt)
+	}
+	_, hasPriv := PrivKeyUnmarshallers[kt]
+	_, hasPub := PubKeyUnmarshallers[kt]
+	if hasPriv || hasPub {
+		return fmt.Errorf("key type %d is already registered", kt)
+	}
+
+	if unmarshalPriv != nil {
+		PrivKeyUnmarshallers[kt] = unmarshalPriv
+	}
+	if unmarshalPub != nil {
+		PubKeyUnmarshallers[kt] = unmarshalPub
+	}
+	return nil
+}
+
+// unregisterKeyType removes the unmarshallers registered for a key type. It
+// lets tests undo RegisterKeyType.
+func unregisterKeyType(kt pb.KeyType) {
+	delete(PrivKeyUnmarshallers, kt)
+	delete(PubKeyUnmarshallers, kt)
+}
+
+// KeyTypeFromString returns the key type with the given name, e.g. "ed25519"
+// or "rsa". Names are matched case-insensitively against the names returned
+// by pb.KeyType.String, so key types round-trip through their names.
+func KeyTypeFromString(s string) (pb.KeyType, error) {
+	names := make([]string, len(KeyTypes))
+	for i, kt := range KeyTypes {
+		names[i] = pb.KeyType(kt).String()
+		if strings.EqualFold(names[i], s) {
+			return pb.KeyType(kt), nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q, expected one of: %s", ErrBadKeyType, s, strings.Join(names, ", "))
 }
 
 // Key represents a crypto key that can be compared to another key
@@ -87,6 +153,9 @@ type Key interface {
 }
 
 // PrivKey represents a private key that can be used to generate a public key and sign data
+//
+// Implementations of Equals for private keys must compare the secret key
+// material in constant time.
 type PrivKey interface {
 	Key
 
@@ -124,6 +193,8 @@ func GenerateKeyPairWithReader(typ, bits int, src io.Reader) (PrivKey, PubKey, e
 		return GenerateSecp256k1Key(src)
 	case ECDSA:
 		return GenerateECDSAKeyPair(src)
+	case Ed448:
+		return GenerateEd448Key(src)
 	default:
 		return nil, nil, ErrBadKeyType
 	}
@@ -286,12 +357,35 @@ func UnmarshalPublicKey(data []byte) (PubKey, error) {
 	return PublicKeyFromProto(pmes)
 }
 
+// UnmarshalPublicKeyWithLimit is like UnmarshalPublicKey, but rejects RSA
+// keys larger than maxRsaKeyBits (instead of MaxRsaKeyBits) with
+// ErrKeyTooLarge. The size is checked before the key is parsed, so this is
+// safe to use on keys received from untrusted peers.
+func UnmarshalPublicKeyWithLimit(data []byte, maxRsaKeyBits int) (PubKey, error) {
+	pmes := new(pb.PublicKey)
+	err := proto.Unmarshal(data, pmes)
+	if err != nil {
+		return nil, err
+	}
+
+	if pmes.GetType() != pb.KeyType_RSA {
+		return PublicKeyFromProto(pmes)
+	}
+
+	pk, err := unmarshalRsaPublicKey(pmes.GetData(), maxRsaKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	pk.(*RsaPublicKey).cached, _ = pmes.Marshal()
+	return pk, nil
+}
+
 // PublicKeyFromProto converts an unserialized protobuf PublicKey message
 // into its representative object.
 func PublicKeyFromProto(pmes *pb.PublicKey) (PubKey, error) {
 	um, ok := PubKeyUnmarshallers[pmes.GetType()]
 	if !ok {
-		return nil, ErrBadKeyType
+		return nil, fmt.Errorf("%w: %d", ErrBadKeyType, pmes.GetType())
 	}
 
 	data := pmes.GetData()
@@ -344,7 +438,7 @@ func UnmarshalPrivateKey(data []byte) (PrivKey, error) {
 
 	um, ok := PrivKeyUnmarshallers[pmes.GetType()]
 	if !ok {
-		return nil, ErrBadKeyType
+		return nil, fmt.Errorf("%w: %d", ErrBadKeyType, pmes.GetType())
 	}
 
 	return um(pmes.GetData())
@@ -382,6 +476,36 @@ func KeyEqual(k1, k2 Key) bool {
 	return k1.Equals(k2)
 }
 
+// KeyFingerprint returns a short, stable identifier of a key, suitable for
+// logs and config files. It is the hex-encoded SHA-256 digest of the
+// protobuf-serialized public key (see MarshalPublicKey). For private keys,
+// the fingerprint of the corresponding public key is returned, so a key pair
+// always shares the same fingerprint.
+func KeyFingerprint(k Key) (string, error) {
+	var pub PubKey
+	switch k := k.(type) {
+	case PrivKey:
+		pub = k.GetPublic()
+	case PubKey:
+		pub = k
+	default:
+		return "", ErrBadKeyType
+	}
+
+	data, err := MarshalPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// secretIntsEqual compares two secret integers of at most size bytes without
+// leaking their value through timing.
+func secretIntsEqual(a, b *big.Int, size int) bool {
+	return subtle.ConstantTimeCompare(padBytes(a.Bytes(), size), padBytes(b.Bytes(), size)) == 1
+}
+
 func basicEquals(k1, k2 Key) bool {
 	if k1.Type() != k2.Type() {
 		return false
@@ -40,6 +40,10 @@ func KeyPairFromStdKey(priv crypto.PrivateKey) (PrivKey, PubKey, error) {
 }
 
 // PrivKeyToStdKey converts libp2p/go-libp2p-core/crypto private keys to standard library (and secp256k1) private keys
+//
+// RSA, ECDSA and Ed25519 keys are returned as *rsa.PrivateKey, *ecdsa.PrivateKey and *ed25519.PrivateKey. As
+// secp256k1 has no standard library type, Secp256k1 keys are returned as *Secp256k1PrivateKey, which can be
+// converted to a *btcec.PrivateKey.
 func PrivKeyToStdKey(priv PrivKey) (crypto.PrivateKey, error) {
 	if priv == nil {
 		return nil, ErrNilPrivateKey
@@ -59,7 +63,10 @@ func PrivKeyToStdKey(priv PrivKey) (crypto.PrivateKey, error) {
 	}
 }
 
-// PubKeyToStdKey converts libp2p/go-libp2p-core/crypto private keys to standard library (and secp256k1) public keys
+// PubKeyToStdKey converts libp2p/go-libp2p-core/crypto public keys to standard library (and secp256k1) public keys
+//
+// RSA, ECDSA and Ed25519 keys are returned as *rsa.PublicKey, *ecdsa.PublicKey and ed25519.PublicKey. Secp256k1
+// keys are returned as *Secp256k1PublicKey, which can be converted to a *btcec.PublicKey.
 func PubKeyToStdKey(pub PubKey) (crypto.PublicKey, error) {
 	if pub == nil {
 		return nil, ErrNilPublicKey
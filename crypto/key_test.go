@@ -9,8 +9,12 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	btcec "github.com/btcsuite/btcd/btcec"
@@ -324,3 +328,230 @@ func TestPanicOnUnknownCipherType(t *testing.T) {
 	passed = true
 	KeyStretcher("Fooba", "SHA1", []byte("foo"))
 }
+
+func TestKeyFingerprint(t *testing.T) {
+	// The fingerprint must be stable across runs and library versions, so
+	// pin it against a fixture.
+	pubBytes, err := ioutil.ReadFile("test_data/2.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privBytes, err := ioutil.ReadFile("test_data/2.priv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := UnmarshalPublicKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := UnmarshalPrivateKey(privBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = "178c3aa8ea20e0393c936d0f0d761df4ddbc3165d5da4850312bb7d98321c727"
+	for _, k := range []Key{pub, priv} {
+		fp, err := KeyFingerprint(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fp != expected {
+			t.Fatalf("expected fingerprint %s, got %s", expected, fp)
+		}
+	}
+
+	_, other, err := test.RandTestKeyPair(Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := KeyFingerprint(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp == expected {
+		t.Fatal("different keys must have different fingerprints")
+	}
+}
+
+func TestRegisterKeyType(t *testing.T) {
+	const custom = pb.KeyType(100)
+
+	priv, pub, err := test.RandTestKeyPair(Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := pub.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := (&pb.PublicKey{Type: custom, Data: raw}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = UnmarshalPublicKey(data)
+	if !errors.Is(err, ErrBadKeyType) {
+		t.Fatalf("expected ErrBadKeyType, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "100") {
+		t.Fatalf("expected the error to mention the key type, got %q", err)
+	}
+
+	if err := RegisterKeyType(custom, nil, UnmarshalEd25519PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterKeyType(custom)
+	pub2, err := UnmarshalPublicKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equals(pub2) {
+		t.Fatal("keys are not equal")
+	}
+
+	if err := RegisterKeyType(custom, nil, UnmarshalEd25519PublicKey); err == nil {
+		t.Fatal("expected re-registering a key type to fail")
+	}
+	if err := RegisterKeyType(pb.KeyType_Ed25519, UnmarshalEd25519PrivateKey, UnmarshalEd25519PublicKey); err == nil {
+		t.Fatal("expected re-registering a built-in key type to fail")
+	}
+
+	// Only a public key unmarshaller was registered.
+	privRaw, err := priv.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privData, err := (&pb.PrivateKey{Type: custom, Data: privRaw}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalPrivateKey(privData); !errors.Is(err, ErrBadKeyType) {
+		t.Fatalf("expected ErrBadKeyType, got %v", err)
+	}
+}
+
+func TestPrivKeyEqualsSecret(t *testing.T) {
+	flip := func(i *big.Int) *big.Int {
+		return new(big.Int).Xor(i, big.NewInt(1))
+	}
+
+	for _, typ := range KeyTypes {
+		if typ == Ed448 {
+			// Not convertible to a standard library key.
+			continue
+		}
+		bits := 512
+		if typ == RSA {
+			bits = 2048
+		}
+		priv, _, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(priv.Type().String(), func(t *testing.T) {
+			privB, err := MarshalPrivateKey(priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			same, err := UnmarshalPrivateKey(privB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !priv.Equals(same) || !same.Equals(priv) {
+				t.Fatal("equal keys must compare equal")
+			}
+
+			// Build a key that shares the public half, but differs in a
+			// single bit of the secret.
+			std, err := PrivKeyToStdKey(priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var modified interface{}
+			switch sk := std.(type) {
+			case *rsa.PrivateKey:
+				cp := *sk
+				cp.D = flip(sk.D)
+				modified = &cp
+			case *ecdsa.PrivateKey:
+				cp := *sk
+				cp.D = flip(sk.D)
+				modified = &cp
+			case *ed25519.PrivateKey:
+				cp := append(ed25519.PrivateKey{}, *sk...)
+				cp[0] ^= 1
+				modified = &cp
+			case *Secp256k1PrivateKey:
+				cp := *(*btcec.PrivateKey)(sk)
+				cp.D = flip(sk.D)
+				modified = &cp
+			default:
+				t.Fatalf("unexpected key type %T", std)
+			}
+			other, _, err := KeyPairFromStdKey(modified)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if priv.Equals(other) || other.Equals(priv) {
+				t.Fatal("keys differing in their secret must not compare equal")
+			}
+		})
+	}
+}
+
+func TestStdKeySignsCompatibly(t *testing.T) {
+	data := []byte("hello! and welcome to some awesome crypto primitives")
+	digest := sha256.Sum256(data)
+
+	for _, typ := range []int{RSA, ECDSA, Ed25519} {
+		priv, pub, err := GenerateKeyPair(typ, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdPriv, err := PrivKeyToStdKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, ok := stdPriv.(crypto.Signer)
+		if !ok {
+			t.Fatalf("expected a crypto.Signer, got %T", stdPriv)
+		}
+
+		var sig []byte
+		if typ == Ed25519 {
+			sig, err = signer.Sign(rand.Reader, data, crypto.Hash(0))
+		} else {
+			sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err = pub.Verify(data, sig)
+		if err != nil || !ok {
+			t.Fatalf("expected the signature of the %T to verify: %v", stdPriv, err)
+		}
+	}
+}
+
+func TestKeyTypeFromString(t *testing.T) {
+	for _, kt := range KeyTypes {
+		name := pb.KeyType(kt).String()
+		for _, s := range []string{name, strings.ToLower(name), strings.ToUpper(name)} {
+			parsed, err := KeyTypeFromString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed != pb.KeyType(kt) {
+				t.Fatalf("expected %s, got %s", name, parsed)
+			}
+		}
+	}
+
+	_, err := KeyTypeFromString("dsa")
+	if !errors.Is(err, ErrBadKeyType) {
+		t.Fatalf("expected ErrBadKeyType, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Ed25519") {
+		t.Fatalf("expected the error to list the valid key types, got %v", err)
+	}
+}
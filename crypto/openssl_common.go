@@ -108,12 +108,16 @@ func (sk *opensslPrivateKey) Raw() ([]byte, error) {
 	return sk.key.MarshalPKCS1PrivateKeyDER()
 }
 
-// Equals checks whether this key is equal to another
+// Equals checks whether this key is equal to another, in constant time
 func (sk *opensslPrivateKey) Equals(k Key) bool {
 	k0, ok := k.(*RsaPrivateKey)
 	if !ok {
 		return basicEquals(sk, k)
 	}
 
-	return sk.key.Equal(k0.opensslPrivateKey.key)
+	// Only compares the public half.
+	if !sk.key.Equal(k0.opensslPrivateKey.key) {
+		return false
+	}
+	return basicEquals(sk, k0)
 }
@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// PEMFormat selects how keys are encoded inside a PEM block.
+type PEMFormat int
+
+const (
+	// PEMFormatLibp2p wraps the libp2p protobuf encoding of a key (see
+	// MarshalPrivateKey and MarshalPublicKey). It supports all key types.
+	PEMFormatLibp2p PEMFormat = iota
+	// PEMFormatStandard uses the standard PKCS#8 (private keys) and SPKI
+	// (public keys) encodings that tools like openssl understand. It is only
+	// supported for RSA and ECDSA keys.
+	PEMFormatStandard
+)
+
+const (
+	// PEMTypeLibp2pPrivateKey is the PEM block type of private keys encoded
+	// with PEMFormatLibp2p.
+	PEMTypeLibp2pPrivateKey = "LIBP2P PRIVATE KEY"
+	// PEMTypeLibp2pPublicKey is the PEM block type of public keys encoded
+	// with PEMFormatLibp2p.
+	PEMTypeLibp2pPublicKey = "LIBP2P PUBLIC KEY"
+
+	pemTypePKCS8PrivateKey = "PRIVATE KEY"
+	pemTypeSPKIPublicKey   = "PUBLIC KEY"
+)
+
+// ErrNoPEMData is returned when no PEM block could be found in the input.
+var ErrNoPEMData = errors.New("no PEM data found")
+
+// MarshalPrivateKeyPEM encodes a private key as a PEM block, using the given
+// format.
+func MarshalPrivateKeyPEM(k PrivKey, format PEMFormat) ([]byte, error) {
+	switch format {
+	case PEMFormatLibp2p:
+		data, err := MarshalPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: PEMTypeLibp2pPrivateKey, Bytes: data}), nil
+	case PEMFormatStandard:
+		if t := k.Type(); t != RSA && t != ECDSA {
+			return nil, fmt.Errorf("standard PEM encoding is not supported for %s keys", t)
+		}
+		std, err := PrivKeyToStdKey(k)
+		if err != nil {
+			return nil, err
+		}
+		data, err := x509.MarshalPKCS8PrivateKey(std)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemTypePKCS8PrivateKey, Bytes: data}), nil
+	default:
+		return nil, fmt.Errorf("unknown PEM format: %d", format)
+	}
+}
+
+// UnmarshalPrivateKeyPEM decodes a private key from the first PEM block in
+// data. Both PEMFormatLibp2p and PEMFormatStandard encoded keys are accepted.
+func UnmarshalPrivateKeyPEM(data []byte) (PrivKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPEMData
+	}
+
+	switch block.Type {
+	case PEMTypeLibp2pPrivateKey:
+		return UnmarshalPrivateKey(block.Bytes)
+	case pemTypePKCS8PrivateKey:
+		std, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch sk := std.(type) {
+		case *rsa.PrivateKey:
+			// Go through the regular path so that the key size is checked.
+			return UnmarshalRsaPrivateKey(x509.MarshalPKCS1PrivateKey(sk))
+		case *ecdsa.PrivateKey:
+			priv, _, err := ECDSAKeyPairFromKey(sk)
+			return priv, err
+		default:
+			return nil, ErrBadKeyType
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block type: %s", block.Type)
+	}
+}
+
+// MarshalPublicKeyPEM encodes a public key as a PEM block, using the given
+// format.
+func MarshalPublicKeyPEM(k PubKey, format PEMFormat) ([]byte, error) {
+	switch format {
+	case PEMFormatLibp2p:
+		data, err := MarshalPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: PEMTypeLibp2pPublicKey, Bytes: data}), nil
+	case PEMFormatStandard:
+		if t := k.Type(); t != RSA && t != ECDSA {
+			return nil, fmt.Errorf("standard PEM encoding is not supported for %s keys", t)
+		}
+		// RSA and ECDSA public keys are already SPKI-encoded.
+		data, err := k.Raw()
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemTypeSPKIPublicKey, Bytes: data}), nil
+	default:
+		return nil, fmt.Errorf("unknown PEM format: %d", format)
+	}
+}
+
+// UnmarshalPublicKeyPEM decodes a public key from the first PEM block in
+// data. Both PEMFormatLibp2p and PEMFormatStandard encoded keys are accepted.
+func UnmarshalPublicKeyPEM(data []byte) (PubKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPEMData
+	}
+
+	switch block.Type {
+	case PEMTypeLibp2pPublicKey:
+		return UnmarshalPublicKey(block.Bytes)
+	case pemTypeSPKIPublicKey:
+		std, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch std.(type) {
+		case *rsa.PublicKey:
+			return UnmarshalRsaPublicKey(block.Bytes)
+		case *ecdsa.PublicKey:
+			return UnmarshalECDSAPublicKey(block.Bytes)
+		default:
+			return nil, ErrBadKeyType
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block type: %s", block.Type)
+	}
+}
@@ -0,0 +1,105 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestPEMRoundTrip(t *testing.T) {
+	for _, typ := range KeyTypes {
+		bits := 512
+		if typ == RSA {
+			bits = 2048
+		}
+		sk, pk, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(sk.Type().String(), func(t *testing.T) {
+			formats := []PEMFormat{PEMFormatLibp2p}
+			if typ == RSA || typ == ECDSA {
+				formats = append(formats, PEMFormatStandard)
+			}
+
+			for _, format := range formats {
+				skPEM, err := MarshalPrivateKeyPEM(sk, format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sk2, err := UnmarshalPrivateKeyPEM(skPEM)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if sk2.Type() != sk.Type() || !sk.Equals(sk2) {
+					t.Fatal("private key didn't survive PEM round-trip")
+				}
+				raw, _ := sk.Raw()
+				raw2, _ := sk2.Raw()
+				if !bytes.Equal(raw, raw2) {
+					t.Fatal("raw private key bytes changed during PEM round-trip")
+				}
+
+				pkPEM, err := MarshalPublicKeyPEM(pk, format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pk2, err := UnmarshalPublicKeyPEM(pkPEM)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if pk2.Type() != pk.Type() || !pk.Equals(pk2) {
+					t.Fatal("public key didn't survive PEM round-trip")
+				}
+			}
+		})
+	}
+}
+
+func TestPEMStandardFormat(t *testing.T) {
+	sk, pk, err := test.RandTestKeyPair(ECDSA, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	skPEM, err := MarshalPrivateKeyPEM(sk, PEMFormatStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(skPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		t.Fatal("expected a PKCS#8 PEM block")
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		t.Fatal(err)
+	}
+
+	pkPEM, err := MarshalPublicKeyPEM(pk, PEMFormatStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ = pem.Decode(pkPEM)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		t.Fatal("expected a SPKI PEM block")
+	}
+	if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		t.Fatal(err)
+	}
+
+	edSk, _, err := test.RandTestKeyPair(Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MarshalPrivateKeyPEM(edSk, PEMFormatStandard); err == nil {
+		t.Fatal("expected an error for ed25519 keys in standard format")
+	}
+
+	if _, err := UnmarshalPrivateKeyPEM([]byte("garbage")); err != ErrNoPEMData {
+		t.Fatalf("expected ErrNoPEMData, got %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"math/big"
+	"math/bits"
+
+	sha256 "github.com/minio/sha256-simd"
+)
+
+// signRFC6979 signs the given hash with priv, deriving the nonce from the
+// private key and the hash as specified in RFC 6979 (using HMAC-SHA256).
+// Signing the same hash with the same key thus always yields the same
+// signature, and doesn't depend on the quality of a random source.
+//
+// The private key and the nonce only go through the constant-time arithmetic
+// of scalarField: math/big only sees public values (the curve order, r and s).
+func signRFC6979(priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int) {
+	c := priv.Curve
+	f := newScalarField(c.Params().N)
+
+	d := f.fromBytes(padBytes(priv.D.Bytes(), f.size))
+	e := f.reduce(f.bits2int(hash))
+	dm := f.toMont(d)
+	em := f.toMont(e)
+
+	nonces := newRFC6979Nonces(f, d, e)
+	for {
+		k := nonces.next()
+		x, _ := c.ScalarBaseMult(f.toBytes(k))
+		r = new(big.Int).Mod(x, f.order)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		// s = k⁻¹(e + d·r) mod n
+		rm := f.toMont(f.fromBytes(padBytes(r.Bytes(), f.size)))
+		sm := f.mul(f.add(f.mul(dm, rm), em), f.inv(f.toMont(k)))
+		s = new(big.Int).SetBytes(f.toBytes(f.fromMont(sm)))
+		if s.Sign() != 0 {
+			return r, s
+		}
+	}
+}
+
+// rfc6979Nonces generates the candidate nonces of RFC 6979, section 3.2.
+type rfc6979Nonces struct {
+	f     *scalarField
+	k, v  []byte
+	first bool
+}
+
+func newRFC6979Nonces(f *scalarField, x, h scalar) *rfc6979Nonces {
+	g := &rfc6979Nonces{
+		f:     f,
+		k:     make([]byte, sha256.Size),
+		v:     make([]byte, sha256.Size),
+		first: true,
+	}
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+
+	// h is bits2octets(h1): bits2int(h1) reduced modulo n.
+	seed := append(f.toBytes(x), f.toBytes(h)...)
+
+	g.k = g.mac(g.v, []byte{0x00}, seed)
+	g.v = g.mac(g.v)
+	g.k = g.mac(g.v, []byte{0x01}, seed)
+	g.v = g.mac(g.v)
+	return g
+}
+
+// next returns the next candidate nonce, in [1, n-1].
+func (g *rfc6979Nonces) next() scalar {
+	for {
+		if !g.first {
+			g.k = g.mac(g.v, []byte{0x00})
+			g.v = g.mac(g.v)
+		}
+		g.first = false
+
+		t := make([]byte, 0, g.f.size+sha256.Size)
+		for len(t) < g.f.size {
+			g.v = g.mac(g.v)
+			t = append(t, g.v...)
+		}
+		if k := g.f.bits2int(t); g.f.isValid(k) {
+			return k
+		}
+	}
+}
+
+func (g *rfc6979Nonces) mac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, g.k)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// scalar is an integer modulo the order of a curve, as little-endian 64-bit
+// limbs. Depending on the operation, it is either in the standard or in the
+// Montgomery representation.
+type scalar []uint64
+
+// scalarField implements the arithmetic modulo the (odd) order n of a curve.
+// All the operations run in constant time with respect to the values of their
+// operands, and only depend on the size of n.
+type scalarField struct {
+	order  *big.Int
+	n      scalar
+	n0inv  uint64 // -n⁻¹ mod 2⁶⁴
+	rr     scalar // R² mod n, with R = 2^(64·len(n))
+	bitLen int
+	size   int // length of n in bytes
+}
+
+func newScalarField(order *big.Int) *scalarField {
+	f := &scalarField{
+		order:  order,
+		n:      make(scalar, (order.BitLen()+63)/64),
+		bitLen: order.BitLen(),
+		size:   (order.BitLen() + 7) / 8,
+	}
+	f.n = f.fromBytes(order.Bytes())
+
+	// Newton's iteration doubles the number of correct low bits of the
+	// inverse at each step, starting from 1 bit (n is odd).
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.n[0]*inv
+	}
+	f.n0inv = -inv
+
+	rr := new(big.Int).Lsh(big.NewInt(1), uint(128*len(f.n)))
+	rr.Mod(rr, order)
+	f.rr = f.fromBytes(rr.Bytes())
+	return f
+}
+
+// fromBytes decodes the big-endian integer b, which must fit in the limbs of
+// a scalar.
+func (f *scalarField) fromBytes(b []byte) scalar {
+	x := make(scalar, len(f.n))
+	for i, v := range b {
+		pos := len(b) - 1 - i
+		x[pos/8] |= uint64(v) << (8 * uint(pos%8))
+	}
+	return x
+}
+
+// toBytes encodes x < n as a big-endian integer of the size of n.
+func (f *scalarField) toBytes(x scalar) []byte {
+	b := make([]byte, f.size)
+	for i := range b {
+		pos := f.size - 1 - i
+		b[i] = byte(x[pos/8] >> (8 * uint(pos%8)))
+	}
+	return b
+}
+
+// bits2int converts b to an integer, keeping its leftmost bits up to the bit
+// length of n. The result may exceed n, but is less than 2n.
+func (f *scalarField) bits2int(b []byte) scalar {
+	if len(b) > f.size {
+		b = b[:f.size]
+	}
+	x := f.fromBytes(b)
+	if excess := len(b)*8 - f.bitLen; excess > 0 {
+		shift := uint(excess)
+		for i := range x {
+			x[i] >>= shift
+			if i+1 < len(x) {
+				x[i] |= x[i+1] << (64 - shift)
+			}
+		}
+	}
+	return x
+}
+
+// reduce reduces x < 2n modulo n.
+func (f *scalarField) reduce(x scalar) scalar {
+	return f.subIfNotLess(x, 0)
+}
+
+// isValid reports whether x is in [1, n-1]. It doesn't leak more than its
+// result.
+func (f *scalarField) isValid(x scalar) bool {
+	var acc, borrow uint64
+	for i := range x {
+		acc |= x[i]
+		_, borrow = bits.Sub64(x[i], f.n[i], borrow)
+	}
+	nonZero := (acc | -acc) >> 63
+	return nonZero&borrow == 1
+}
+
+// subIfNotLess returns (hi:x) - n if (hi:x) ≥ n, and x otherwise, where hi is
+// an extra high limb. (hi:x) must be less than 2n.
+func (f *scalarField) subIfNotLess(x scalar, hi uint64) scalar {
+	d := make(scalar, len(f.n))
+	var borrow uint64
+	for i := range d {
+		d[i], borrow = bits.Sub64(x[i], f.n[i], borrow)
+	}
+	_, borrow = bits.Sub64(hi, 0, borrow)
+
+	// borrow is 1 iff (hi:x) < n, in which case x is kept.
+	keep := -borrow
+	for i := range d {
+		d[i] = d[i]&^keep | x[i]&keep
+	}
+	return d
+}
+
+// add returns a + b mod n, for a, b < n.
+func (f *scalarField) add(a, b scalar) scalar {
+	sum := make(scalar, len(f.n))
+	var carry uint64
+	for i := range sum {
+		sum[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	return f.subIfNotLess(sum, carry)
+}
+
+// mul returns the Montgomery product a·b·R⁻¹ mod n, for a, b < n.
+func (f *scalarField) mul(a, b scalar) scalar {
+	l := len(f.n)
+	t := make(scalar, l+2)
+	for i := 0; i < l; i++ {
+		var c uint64
+		for j := 0; j < l; j++ {
+			c, t[j] = mulAdd(a[j], b[i], t[j], c)
+		}
+		t[l], c = bits.Add64(t[l], c, 0)
+		t[l+1] = c
+
+		m := t[0] * f.n0inv
+		c, _ = mulAdd(m, f.n[0], t[0], 0)
+		for j := 1; j < l; j++ {
+			c, t[j-1] = mulAdd(m, f.n[j], t[j], c)
+		}
+		t[l-1], c = bits.Add64(t[l], c, 0)
+		t[l] = t[l+1] + c
+	}
+	return f.subIfNotLess(t[:l], t[l])
+}
+
+// mulAdd returns x·y + a + c as a 128-bit integer.
+func mulAdd(x, y, a, c uint64) (hi, lo uint64) {
+	hi, lo = bits.Mul64(x, y)
+	var carry uint64
+	lo, carry = bits.Add64(lo, a, 0)
+	hi += carry
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	return hi, lo
+}
+
+// toMont converts x to the Montgomery representation x·R mod n.
+func (f *scalarField) toMont(x scalar) scalar {
+	return f.mul(x, f.rr)
+}
+
+// fromMont converts x from the Montgomery representation.
+func (f *scalarField) fromMont(x scalar) scalar {
+	one := make(scalar, len(f.n))
+	one[0] = 1
+	return f.mul(x, one)
+}
+
+// inv returns the inverse of x modulo n (which is prime), both in the
+// Montgomery representation, computing x^(n-2). The exponent is public, so
+// branching on its bits doesn't leak anything about x.
+func (f *scalarField) inv(x scalar) scalar {
+	exp := new(big.Int).Sub(f.order, big.NewInt(2))
+	one := make(scalar, len(f.n))
+	one[0] = 1
+	acc := f.toMont(one)
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		acc = f.mul(acc, acc)
+		if exp.Bit(i) == 1 {
+			acc = f.mul(acc, x)
+		}
+	}
+	return acc
+}
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"errors"
 	"fmt"
 	"os"
 )
@@ -12,10 +13,23 @@ const WeakRsaKeyEnv = "LIBP2P_ALLOW_WEAK_RSA_KEYS"
 
 var MinRsaKeyBits = 2048
 
+// MaxRsaKeyBits is the maximum size of RSA keys accepted when unmarshalling.
+// Larger keys are rejected with ErrKeyTooLarge before being parsed, so that
+// peers can't make us waste resources on absurdly large keys.
+var MaxRsaKeyBits = 8192
+
 // ErrRsaKeyTooSmall is returned when trying to generate or parse an RSA key
 // that's smaller than MinRsaKeyBits bits. In test
 var ErrRsaKeyTooSmall error
 
+// ErrKeyTooLarge is returned when trying to parse a key that's larger than
+// the allowed maximum (see MaxRsaKeyBits and UnmarshalPublicKeyWithLimit).
+var ErrKeyTooLarge = errors.New("key is too large")
+
+// ErrInvalidRsaExponent is returned when trying to generate an RSA key with a
+// public exponent that isn't odd and greater than 1.
+var ErrInvalidRsaExponent = errors.New("rsa public exponent must be odd and greater than 1")
+
 func init() {
 	if _, ok := os.LookupEnv(WeakRsaKeyEnv); ok {
 		MinRsaKeyBits = 512
@@ -23,3 +37,30 @@ func init() {
 
 	ErrRsaKeyTooSmall = fmt.Errorf("rsa keys must be >= %d bits to be useful", MinRsaKeyBits)
 }
+
+// checkRsaPublicKeyLen cheaply rejects encoded (PKIX) RSA public keys that
+// can't possibly fit in maxBits bits, before any parsing happens. The slack
+// covers the DER framing, the algorithm identifier and the exponent.
+func checkRsaPublicKeyLen(b []byte, maxBits int) error {
+	if len(b) > maxBits/8+64 {
+		return ErrKeyTooLarge
+	}
+	return nil
+}
+
+// checkRsaPrivateKeyLen is like checkRsaPublicKeyLen, for encoded (PKCS#1)
+// private keys. Those hold two full-size integers (the modulus and the
+// private exponent) and five half-size ones.
+func checkRsaPrivateKeyLen(b []byte, maxBits int) error {
+	if len(b) > 5*(maxBits/8)+128 {
+		return ErrKeyTooLarge
+	}
+	return nil
+}
+
+func checkRsaExponent(e int) error {
+	if e <= 1 || e%2 == 0 {
+		return ErrInvalidRsaExponent
+	}
+	return nil
+}
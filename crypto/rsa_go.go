@@ -9,6 +9,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"io"
+	"math/big"
 	"sync"
 
 	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
@@ -42,6 +43,79 @@ func GenerateRSAKeyPair(bits int, src io.Reader) (PrivKey, PubKey, error) {
 	return &RsaPrivateKey{sk: *priv}, &RsaPublicKey{k: pk}, nil
 }
 
+// GenerateRSAKeyPairWithExponent generates a new rsa private and public key,
+// using e as the public exponent instead of the default 65537. e must be odd
+// and greater than 1.
+//
+// WARNING: small exponents such as 3 are only safe with properly padded
+// signatures. They have a history of signature forgeries against lax
+// PKCS#1 v1.5 verifiers (Bleichenbacher's e=3 attack), which is the scheme
+// used to sign with RSA keys. Only use them when required for
+// interoperability, and prefer 65537 otherwise.
+func GenerateRSAKeyPairWithExponent(bits, e int, src io.Reader) (PrivKey, PubKey, error) {
+	if bits < MinRsaKeyBits {
+		return nil, nil, ErrRsaKeyTooSmall
+	}
+	if err := checkRsaExponent(e); err != nil {
+		return nil, nil, err
+	}
+	priv, err := generateRSAKeyWithExponent(src, bits, e)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk := priv.PublicKey
+	return &RsaPrivateKey{sk: *priv}, &RsaPublicKey{k: pk}, nil
+}
+
+// generateRSAKeyWithExponent generates a two-prime RSA key. rsa.GenerateKey
+// can't be used, as it always uses 65537 as the public exponent. Like it, d is
+// the inverse of e modulo λ(n) = lcm(p-1, q-1), and the resulting key is
+// checked with Validate before being returned.
+func generateRSAKeyWithExponent(src io.Reader, bits, e int) (*rsa.PrivateKey, error) {
+	bigE := big.NewInt(int64(e))
+	one := big.NewInt(1)
+	for {
+		p, err := rand.Prime(src, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(src, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			continue
+		}
+
+		// λ(n) = (p-1)(q-1) / gcd(p-1, q-1). If e isn't coprime with it,
+		// there's no d: start over with other primes.
+		pm1 := new(big.Int).Sub(p, one)
+		qm1 := new(big.Int).Sub(q, one)
+		gcd := new(big.Int).GCD(nil, nil, pm1, qm1)
+		lambda := new(big.Int).Mul(pm1, qm1)
+		lambda.Div(lambda, gcd)
+		d := new(big.Int).ModInverse(bigE, lambda)
+		if d == nil {
+			continue
+		}
+
+		priv := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: e},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		if err := priv.Validate(); err != nil {
+			return nil, err
+		}
+		priv.Precompute()
+		return priv, nil
+	}
+}
+
 // Verify compares a signature against input data
 func (pk *RsaPublicKey) Verify(data, sig []byte) (bool, error) {
 	hashed := sha256.Sum256(data)
@@ -88,6 +162,16 @@ func (sk *RsaPrivateKey) Sign(message []byte) ([]byte, error) {
 	return rsa.SignPKCS1v15(rand.Reader, &sk.sk, crypto.SHA256, hashed[:])
 }
 
+// SignStream returns a signature of the data read from r, hashing it
+// incrementally. The signature is the same as the one Sign would return.
+func (sk *RsaPrivateKey) SignStream(r io.Reader) ([]byte, error) {
+	hashed, err := hashStream(r)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.SignPKCS1v15(rand.Reader, &sk.sk, crypto.SHA256, hashed)
+}
+
 // GetPublic returns a public key
 func (sk *RsaPrivateKey) GetPublic() PubKey {
 	return &RsaPublicKey{k: sk.sk.PublicKey}
@@ -107,9 +191,9 @@ func (sk *RsaPrivateKey) Raw() ([]byte, error) {
 	return b, nil
 }
 
-// Equals checks whether this key is equal to another
+// Equals checks whether this key is equal to another, in constant time
 func (sk *RsaPrivateKey) Equals(k Key) bool {
-	// make sure this is an rsa public key
+	// make sure this is an rsa private key
 	other, ok := (k).(*RsaPrivateKey)
 	if !ok {
 		return basicEquals(sk, k)
@@ -118,12 +202,18 @@ func (sk *RsaPrivateKey) Equals(k Key) bool {
 	a := sk.sk
 	b := other.sk
 
-	// Don't care about constant time. We're only comparing the public half.
-	return a.PublicKey.N.Cmp(b.PublicKey.N) == 0 && a.PublicKey.E == b.PublicKey.E
+	// The public half doesn't need to be compared in constant time.
+	if a.PublicKey.N.Cmp(b.PublicKey.N) != 0 || a.PublicKey.E != b.PublicKey.E {
+		return false
+	}
+	return secretIntsEqual(a.D, b.D, (a.N.BitLen()+7)/8)
 }
 
 // UnmarshalRsaPrivateKey returns a private key from the input x509 bytes
 func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
+	if err := checkRsaPrivateKeyLen(b, MaxRsaKeyBits); err != nil {
+		return nil, err
+	}
 	sk, err := x509.ParsePKCS1PrivateKey(b)
 	if err != nil {
 		return nil, err
@@ -131,11 +221,21 @@ func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
 	if sk.N.BitLen() < MinRsaKeyBits {
 		return nil, ErrRsaKeyTooSmall
 	}
+	if sk.N.BitLen() > MaxRsaKeyBits {
+		return nil, ErrKeyTooLarge
+	}
 	return &RsaPrivateKey{sk: *sk}, nil
 }
 
 // UnmarshalRsaPublicKey returns a public key from the input x509 bytes
 func UnmarshalRsaPublicKey(b []byte) (PubKey, error) {
+	return unmarshalRsaPublicKey(b, MaxRsaKeyBits)
+}
+
+func unmarshalRsaPublicKey(b []byte, maxBits int) (PubKey, error) {
+	if err := checkRsaPublicKeyLen(b, maxBits); err != nil {
+		return nil, err
+	}
 	pub, err := x509.ParsePKIXPublicKey(b)
 	if err != nil {
 		return nil, err
@@ -147,6 +247,9 @@ func UnmarshalRsaPublicKey(b []byte) (PubKey, error) {
 	if pk.N.BitLen() < MinRsaKeyBits {
 		return nil, ErrRsaKeyTooSmall
 	}
+	if pk.N.BitLen() > maxBits {
+		return nil, ErrKeyTooLarge
+	}
 
 	return &RsaPublicKey{k: *pk}, nil
 }
@@ -3,6 +3,10 @@
 package crypto
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"errors"
 	"io"
 
@@ -32,13 +36,61 @@ func GenerateRSAKeyPair(bits int, _ io.Reader) (PrivKey, PubKey, error) {
 	return &RsaPrivateKey{opensslPrivateKey{key}}, &RsaPublicKey{opensslPublicKey{key: key}}, nil
 }
 
+// GenerateRSAKeyPairWithExponent generates a new rsa private and public key,
+// using e as the public exponent instead of the default 65537. e must be odd
+// and greater than 1.
+//
+// WARNING: small exponents such as 3 are only safe with properly padded
+// signatures. They have a history of signature forgeries against lax
+// PKCS#1 v1.5 verifiers (Bleichenbacher's e=3 attack), which is the scheme
+// used to sign with RSA keys. Only use them when required for
+// interoperability, and prefer 65537 otherwise.
+func GenerateRSAKeyPairWithExponent(bits, e int, _ io.Reader) (PrivKey, PubKey, error) {
+	if bits < MinRsaKeyBits {
+		return nil, nil, ErrRsaKeyTooSmall
+	}
+	if err := checkRsaExponent(e); err != nil {
+		return nil, nil, err
+	}
+
+	key, err := openssl.GenerateRSAKeyWithExponent(bits, e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &RsaPrivateKey{opensslPrivateKey{key}}, &RsaPublicKey{opensslPublicKey{key: key}}, nil
+}
+
 // GetPublic returns a public key
 func (sk *RsaPrivateKey) GetPublic() PubKey {
 	return &RsaPublicKey{opensslPublicKey{key: sk.opensslPrivateKey.key}}
 }
 
+// SignStream returns a signature of the data read from r, hashing it
+// incrementally. The signature is the same as the one Sign would return.
+//
+// As OpenSSL doesn't sign pre-hashed data through our bindings, the digest
+// is signed with the standard library's implementation.
+func (sk *RsaPrivateKey) SignStream(r io.Reader) ([]byte, error) {
+	hashed, err := hashStream(r)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := sk.Raw()
+	if err != nil {
+		return nil, err
+	}
+	stdKey, err := x509.ParsePKCS1PrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.SignPKCS1v15(rand.Reader, stdKey, crypto.SHA256, hashed)
+}
+
 // UnmarshalRsaPrivateKey returns a private key from the input x509 bytes
 func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
+	if err := checkRsaPrivateKeyLen(b, MaxRsaKeyBits); err != nil {
+		return nil, err
+	}
 	key, err := unmarshalOpensslPrivateKey(b)
 	if err != nil {
 		return nil, err
@@ -46,6 +98,9 @@ func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
 	if 8*key.key.Size() < MinRsaKeyBits {
 		return nil, ErrRsaKeyTooSmall
 	}
+	if 8*key.key.Size() > MaxRsaKeyBits {
+		return nil, ErrKeyTooLarge
+	}
 	if key.Type() != RSA {
 		return nil, errors.New("not actually an rsa public key")
 	}
@@ -54,6 +109,13 @@ func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
 
 // UnmarshalRsaPublicKey returns a public key from the input x509 bytes
 func UnmarshalRsaPublicKey(b []byte) (PubKey, error) {
+	return unmarshalRsaPublicKey(b, MaxRsaKeyBits)
+}
+
+func unmarshalRsaPublicKey(b []byte, maxBits int) (PubKey, error) {
+	if err := checkRsaPublicKeyLen(b, maxBits); err != nil {
+		return nil, err
+	}
 	key, err := unmarshalOpensslPublicKey(b)
 	if err != nil {
 		return nil, err
@@ -61,6 +123,9 @@ func UnmarshalRsaPublicKey(b []byte) (PubKey, error) {
 	if 8*key.key.Size() < MinRsaKeyBits {
 		return nil, ErrRsaKeyTooSmall
 	}
+	if 8*key.key.Size() > maxBits {
+		return nil, ErrKeyTooLarge
+	}
 	if key.Type() != RSA {
 		return nil, errors.New("not actually an rsa public key")
 	}
@@ -2,7 +2,12 @@ package crypto
 
 import (
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"math/big"
 	"testing"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
 )
 
 func TestRSABasicSignAndVerify(t *testing.T) {
@@ -122,3 +127,115 @@ func TestRSAMarshalLoop(t *testing.T) {
 		t.Fatal("keys are not equal")
 	}
 }
+
+func TestRSALargeKey(t *testing.T) {
+	// A well-formed PKIX encoding of a (non-prime) 100k bit modulus.
+	n := new(big.Int).Lsh(big.NewInt(1), 100000)
+	n.Sub(n, big.NewInt(1))
+	der, err := x509.MarshalPKIXPublicKey(&rsa.PublicKey{N: n, E: 65537})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hugeDer, err := (&pb.PublicKey{Type: pb.KeyType_RSA, Data: der}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pure garbage, large enough to be rejected before parsing.
+	junk, err := (&pb.PublicKey{Type: pb.KeyType_RSA, Data: make([]byte, 1<<20)}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	junkPriv, err := (&pb.PrivateKey{Type: pb.KeyType_RSA, Data: make([]byte, 1<<20)}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]byte{hugeDer, junk} {
+		if _, err := UnmarshalPublicKey(data); err != ErrKeyTooLarge {
+			t.Fatalf("expected ErrKeyTooLarge, got %v", err)
+		}
+		if _, err := UnmarshalPublicKeyWithLimit(data, 4096); err != ErrKeyTooLarge {
+			t.Fatalf("expected ErrKeyTooLarge, got %v", err)
+		}
+	}
+	if _, err := UnmarshalPrivateKey(junkPriv); err != ErrKeyTooLarge {
+		t.Fatalf("expected ErrKeyTooLarge, got %v", err)
+	}
+
+	// A regular key passes the default limit, but not a tighter one.
+	_, pub, err := GenerateRSAKeyPair(2048, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubB, err := MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubNew, err := UnmarshalPublicKeyWithLimit(pubB, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equals(pubNew) {
+		t.Fatal("keys are not equal")
+	}
+	if _, err := UnmarshalPublicKeyWithLimit(pubB, 1024); err != ErrKeyTooLarge {
+		t.Fatalf("expected ErrKeyTooLarge, got %v", err)
+	}
+}
+
+func TestRSAKeyWithExponent(t *testing.T) {
+	for _, e := range []int{3, 65537} {
+		priv, pub, err := GenerateRSAKeyPairWithExponent(2048, e, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := priv.Raw()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdKey, err := x509.ParsePKCS1PrivateKey(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stdKey.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if stdKey.E != e || stdKey.N.BitLen() != 2048 {
+			t.Fatalf("expected a 2048-bit key with exponent %d, got a %d-bit key with exponent %d", e, stdKey.N.BitLen(), stdKey.E)
+		}
+
+		privB, err := MarshalPrivateKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privNew, err := UnmarshalPrivateKey(privB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubB, err := MarshalPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubNew, err := UnmarshalPublicKey(pubB)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := []byte("hello! and welcome to some awesome crypto primitives")
+		sig, err := privNew.Sign(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := pubNew.Verify(data, sig)
+		if err != nil || !ok {
+			t.Fatalf("signature didn't match: %v", err)
+		}
+	}
+
+	for _, e := range []int{-3, 1, 4} {
+		if _, _, err := GenerateRSAKeyPairWithExponent(2048, e, rand.Reader); err != ErrInvalidRsaExponent {
+			t.Fatalf("expected exponent %d to be rejected, got %v", e, err)
+		}
+	}
+}
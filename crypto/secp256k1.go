@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"io"
 
@@ -47,6 +48,16 @@ func UnmarshalSecp256k1PublicKey(data []byte) (PubKey, error) {
 	return (*Secp256k1PublicKey)(k), nil
 }
 
+// UnmarshalSecp256k1PublicKeyUncompressed returns a public key from its
+// 65-byte uncompressed form, as returned by RawUncompressed. The key is
+// still marshaled in the compressed form.
+func UnmarshalSecp256k1PublicKeyUncompressed(data []byte) (PubKey, error) {
+	if len(data) != btcec.PubKeyBytesLenUncompressed {
+		return nil, fmt.Errorf("expected uncompressed secp256k1 data size to be %d", btcec.PubKeyBytesLenUncompressed)
+	}
+	return UnmarshalSecp256k1PublicKey(data)
+}
+
 // Bytes returns protobuf bytes from a private key
 func (k *Secp256k1PrivateKey) Bytes() ([]byte, error) {
 	return MarshalPrivateKey(k)
@@ -62,20 +73,37 @@ func (k *Secp256k1PrivateKey) Raw() ([]byte, error) {
 	return (*btcec.PrivateKey)(k).Serialize(), nil
 }
 
-// Equals compares two private keys
+// Equals compares two private keys in constant time
 func (k *Secp256k1PrivateKey) Equals(o Key) bool {
 	sk, ok := o.(*Secp256k1PrivateKey)
 	if !ok {
 		return basicEquals(k, o)
 	}
 
-	return k.GetPublic().Equals(sk.GetPublic())
+	a := (*btcec.PrivateKey)(k).Serialize()
+	b := (*btcec.PrivateKey)(sk).Serialize()
+	return subtle.ConstantTimeCompare(a, b) == 1
 }
 
-// Sign returns a signature from input data
+// Sign returns a signature from input data. Signatures are deterministic:
+// btcec derives the nonce from the key and the data as specified in RFC 6979.
 func (k *Secp256k1PrivateKey) Sign(data []byte) ([]byte, error) {
 	hash := sha256.Sum256(data)
-	sig, err := (*btcec.PrivateKey)(k).Sign(hash[:])
+	return k.signHash(hash[:])
+}
+
+// SignStream returns a signature of the data read from r, hashing it
+// incrementally. The signature is the same as the one Sign would return.
+func (k *Secp256k1PrivateKey) SignStream(r io.Reader) ([]byte, error) {
+	hash, err := hashStream(r)
+	if err != nil {
+		return nil, err
+	}
+	return k.signHash(hash)
+}
+
+func (k *Secp256k1PrivateKey) signHash(hash []byte) ([]byte, error) {
+	sig, err := (*btcec.PrivateKey)(k).Sign(hash)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +131,13 @@ func (k *Secp256k1PublicKey) Raw() ([]byte, error) {
 	return (*btcec.PublicKey)(k).SerializeCompressed(), nil
 }
 
+// RawUncompressed returns the 65-byte uncompressed form of the key, as
+// expected by some tools. Raw, and therefore the key's protobuf encoding
+// and the peer IDs derived from it, use the compressed form.
+func (k *Secp256k1PublicKey) RawUncompressed() ([]byte, error) {
+	return (*btcec.PublicKey)(k).SerializeUncompressed(), nil
+}
+
 // Equals compares two public keys
 func (k *Secp256k1PublicKey) Equals(o Key) bool {
 	sk, ok := o.(*Secp256k1PublicKey)
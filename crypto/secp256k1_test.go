@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/rand"
 	"testing"
 )
@@ -94,3 +95,72 @@ func TestSecp256k1MarshalLoop(t *testing.T) {
 	}
 
 }
+
+func TestSecp256k1Uncompressed(t *testing.T) {
+	_, pub, err := GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := pub.(*Secp256k1PublicKey).RawUncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 65 || raw[0] != 0x04 {
+		t.Fatalf("expected a 65-byte uncompressed key, got %x", raw)
+	}
+
+	pubNew, err := UnmarshalSecp256k1PublicKeyUncompressed(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equals(pubNew) {
+		t.Fatal("keys are not equal")
+	}
+
+	// The marshaled key, from which peer IDs are derived, stays compressed.
+	pubB, err := pub.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubNewB, err := pubNew.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pubB, pubNewB) {
+		t.Fatal("expected the marshaled keys to be equal")
+	}
+	compressed, err := pubNew.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) != 33 {
+		t.Fatalf("expected a 33-byte compressed key, got %d bytes", len(compressed))
+	}
+
+	if _, err := UnmarshalSecp256k1PublicKeyUncompressed(compressed); err == nil {
+		t.Fatal("expected compressed keys to be rejected")
+	}
+}
+
+func TestSecp256k1DeterministicSignature(t *testing.T) {
+	priv, pub, err := GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello! and welcome to some awesome crypto primitives")
+	sig1, err := priv.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := priv.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig1, sig2) {
+		t.Fatal("expected signing the same data twice to yield the same signature")
+	}
+	if ok, err := pub.Verify(data, sig1); err != nil || !ok {
+		t.Fatal("signature didn't match")
+	}
+}
@@ -0,0 +1,36 @@
+package crypto
+
+import (
+	"io"
+
+	sha256 "github.com/minio/sha256-simd"
+)
+
+// StreamSigner is an optional interface implemented by private keys which
+// can sign data read from an io.Reader, without buffering it in memory.
+//
+// It is only implemented by the key types that hash the data before signing
+// it (RSA, ECDSA and Secp256k1), as the hash can be computed incrementally.
+// Ed25519 and Ed448 keys, which sign the whole message, don't implement it.
+//
+// SignStream returns the same signature as Sign would for the same data, so
+// it can be verified with the public key's Verify.
+type StreamSigner interface {
+	// SignStream signs the data read from r, until EOF.
+	SignStream(r io.Reader) ([]byte, error)
+}
+
+// SupportsStreamSign returns true if k implements StreamSigner.
+func SupportsStreamSign(k PrivKey) bool {
+	_, ok := k.(StreamSigner)
+	return ok
+}
+
+// hashStream returns the SHA-256 digest of the data read from r.
+func hashStream(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
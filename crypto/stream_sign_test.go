@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+const streamSignSize = 8 << 20
+
+// testStream returns a reader of size pseudo-random bytes.
+func testStream(size int64) io.Reader {
+	return io.LimitReader(rand.New(rand.NewSource(42)), size)
+}
+
+func TestSignStream(t *testing.T) {
+	data, err := ioutil.ReadAll(testStream(streamSignSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range []int{RSA, ECDSA, Secp256k1} {
+		priv, pub, err := GenerateKeyPair(typ, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !SupportsStreamSign(priv) {
+			t.Fatalf("expected key type %d to support stream signing", typ)
+		}
+		sig, err := priv.(StreamSigner).SignStream(testStream(streamSignSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := pub.Verify(data, sig)
+		if err != nil || !ok {
+			t.Fatalf("expected the signature of key type %d to verify: %v", typ, err)
+		}
+		ok, _ = pub.Verify(data[1:], sig)
+		if ok {
+			t.Fatalf("expected the signature of key type %d to fail for other data", typ)
+		}
+	}
+
+	priv, _, err := GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SupportsStreamSign(priv) {
+		t.Fatal("expected ed25519 keys not to support stream signing")
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+
+func TestSignStreamReadError(t *testing.T) {
+	priv, _, err := GenerateKeyPair(ECDSA, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := io.MultiReader(bytes.NewReader([]byte("data")), errReader{})
+	if _, err := priv.(StreamSigner).SignStream(r); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// ErrNotEd25519Key is returned when an X25519 conversion or ECDH is attempted
+// with a key that isn't an Ed25519 key.
+var ErrNotEd25519Key = errors.New("x25519: key is not an ed25519 key")
+
+// curve25519P is the field prime 2^255 - 19.
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// ConvertEd25519PrivateKeyToX25519 converts an Ed25519 private key into the
+// equivalent X25519 private scalar, as specified in RFC 8032 (section 5.1.5)
+// and RFC 7748.
+//
+// The returned scalar is already clamped and can be used directly with
+// golang.org/x/crypto/curve25519.
+func ConvertEd25519PrivateKeyToX25519(priv PrivKey) ([]byte, error) {
+	edk, ok := priv.(*Ed25519PrivateKey)
+	if !ok {
+		return nil, ErrNotEd25519Key
+	}
+
+	h := sha512.Sum512(edk.k.Seed())
+	out := make([]byte, curve25519.ScalarSize)
+	copy(out, h[:curve25519.ScalarSize])
+	out[0] &= 248
+	out[31] &= 127
+	out[31] |= 64
+	return out, nil
+}
+
+// ConvertEd25519PublicKeyToX25519 converts an Ed25519 public key into the
+// equivalent X25519 public key using the birational map between the twisted
+// Edwards curve and the Montgomery curve: u = (1 + y) / (1 - y).
+func ConvertEd25519PublicKeyToX25519(pub PubKey) ([]byte, error) {
+	edk, ok := pub.(*Ed25519PublicKey)
+	if !ok {
+		return nil, ErrNotEd25519Key
+	}
+	if len(edk.k) != ed25519.PublicKeySize {
+		return nil, errors.New("x25519: invalid ed25519 public key size")
+	}
+
+	// The key is the little-endian y coordinate, with the sign of x stored
+	// in the most significant bit.
+	le := make([]byte, ed25519.PublicKeySize)
+	copy(le, edk.k)
+	le[31] &= 0x7f
+	y := new(big.Int).SetBytes(reverse(le))
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errors.New("x25519: invalid ed25519 public key")
+	}
+
+	num := new(big.Int).Add(big.NewInt(1), y)
+	den := new(big.Int).Sub(big.NewInt(1), y)
+	den.Mod(den, curve25519P)
+	if den.Sign() == 0 {
+		return nil, errors.New("x25519: ed25519 public key maps to the point at infinity")
+	}
+	den.ModInverse(den, curve25519P)
+	u := num.Mul(num, den)
+	u.Mod(u, curve25519P)
+
+	out := make([]byte, curve25519.PointSize)
+	ub := u.Bytes()
+	copy(out[curve25519.PointSize-len(ub):], ub)
+	return reverse(out), nil
+}
+
+// ECDH derives a shared secret from a local Ed25519 private key and a remote
+// Ed25519 public key, by converting both to X25519 and performing an X25519
+// Diffie-Hellman exchange.
+//
+// The output is the raw X25519 shared secret. Callers should pass it through
+// a KDF (e.g. HKDF) before using it as a symmetric key.
+func ECDH(priv PrivKey, pub PubKey) ([]byte, error) {
+	scalar, err := ConvertEd25519PrivateKeyToX25519(priv)
+	if err != nil {
+		return nil, err
+	}
+	point, err := ConvertEd25519PublicKeyToX25519(pub)
+	if err != nil {
+		return nil, err
+	}
+	return curve25519.X25519(scalar, point)
+}
+
+// reverse reverses b in place and returns it.
+func reverse(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestX25519Conversion(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		priv, pub, err := GenerateEd25519Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		scalar, err := ConvertEd25519PrivateKeyToX25519(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		point, err := ConvertEd25519PublicKeyToX25519(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The converted public key must match the public key derived from
+		// the converted private key.
+		expected, err := curve25519.X25519(scalar, curve25519.Basepoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(point, expected) {
+			t.Fatalf("converted public key doesn't match the converted private key:\n%x\n%x", point, expected)
+		}
+	}
+}
+
+func TestECDH(t *testing.T) {
+	privA, pubA, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privB, pubB, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretA, err := ECDH(privA, pubB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretB, err := ECDH(privB, pubA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(secretA, secretB) {
+		t.Fatal("peers derived different shared secrets")
+	}
+}
+
+func TestECDHRejectsNonEd25519(t *testing.T) {
+	edPriv, edPub, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPriv, ecPub, err := GenerateECDSAKeyPair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ECDH(ecPriv, edPub); err != ErrNotEd25519Key {
+		t.Fatalf("expected ErrNotEd25519Key, got %v", err)
+	}
+	if _, err := ECDH(edPriv, ecPub); err != ErrNotEd25519Key {
+		t.Fatalf("expected ErrNotEd25519Key, got %v", err)
+	}
+}
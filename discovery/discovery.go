@@ -10,7 +10,14 @@ import (
 
 // Advertiser is an interface for advertising services
 type Advertiser interface {
-	// Advertise advertises a service
+	// Advertise advertises a service, and returns the effective TTL of the
+	// advertisement, i.e. the TTL granted by the backend, which may differ
+	// from the one requested with the TTL option. Callers re-advertising
+	// periodically should schedule the next advertisement based on the
+	// returned TTL. Implementations clamping the requested TTL should do so
+	// with EffectiveTTL.
+	//
+	// The Limit option only applies to FindPeers, and is ignored by Advertise.
 	Advertise(ctx context.Context, ns string, opts ...Option) (time.Duration, error)
 }
 
@@ -25,3 +32,63 @@ type Discovery interface {
 	Advertiser
 	Discoverer
 }
+
+// EffectiveTTL returns the TTL an Advertiser should grant when requested
+// is asked for, given the range [min, max] supported by its backend:
+//
+//  * A requested TTL of zero (or less) means no preference, and max is
+//    granted.
+//  * Otherwise, requested is clamped to [min, max].
+//
+// A max of zero means that there is no upper bound; in that case, a
+// requested TTL of zero grants min.
+func EffectiveTTL(requested, min, max time.Duration) time.Duration {
+	if requested <= 0 {
+		if max > 0 {
+			return max
+		}
+		return min
+	}
+	if requested < min {
+		return min
+	}
+	if max > 0 && requested > max {
+		return max
+	}
+	return requested
+}
+
+// FilterPeers returns a channel forwarding the peers received from in for
+// which filter returns true. The returned channel is closed when in is
+// closed or when ctx is done. A nil filter forwards all peers.
+//
+// FilterPeers doesn't enforce any limit on the number of forwarded peers; see
+// the Filter option.
+func FilterPeers(ctx context.Context, in <-chan peer.AddrInfo, filter func(peer.AddrInfo) bool) <-chan peer.AddrInfo {
+	if filter == nil {
+		return in
+	}
+	out := make(chan peer.AddrInfo)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case pi, ok := <-in:
+				if !ok {
+					return
+				}
+				if !filter(pi) {
+					continue
+				}
+				select {
+				case out <- pi:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// stubDiscoverer returns a fixed set of peers, honoring the Filter option.
+type stubDiscoverer struct {
+	peers []peer.AddrInfo
+}
+
+func (d *stubDiscoverer) FindPeers(ctx context.Context, ns string, opts ...Option) (<-chan peer.AddrInfo, error) {
+	var options Options
+	if err := options.Apply(opts...); err != nil {
+		return nil, err
+	}
+	ch := make(chan peer.AddrInfo, len(d.peers))
+	for _, pi := range d.peers {
+		ch <- pi
+	}
+	close(ch)
+	return FilterPeers(ctx, ch, options.Filter), nil
+}
+
+func TestFilterOption(t *testing.T) {
+	tcpPeer := peer.AddrInfo{
+		ID:    test.RandPeerIDFatal(t),
+		Addrs: []ma.Multiaddr{ma.StringCast("/ip4/1.1.1.1/tcp/4001")},
+	}
+	udpPeer := peer.AddrInfo{
+		ID:    test.RandPeerIDFatal(t),
+		Addrs: []ma.Multiaddr{ma.StringCast("/ip4/1.1.1.1/udp/4001")},
+	}
+	d := &stubDiscoverer{peers: []peer.AddrInfo{udpPeer, tcpPeer}}
+
+	onlyTCP := func(pi peer.AddrInfo) bool {
+		for _, a := range pi.Addrs {
+			if _, err := a.ValueForProtocol(ma.P_TCP); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	ch, err := d.FindPeers(context.Background(), "ns", Filter(onlyTCP))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []peer.AddrInfo
+	for pi := range ch {
+		found = append(found, pi)
+	}
+	if len(found) != 1 || found[0].ID != tcpPeer.ID {
+		t.Fatalf("expected only the TCP peer, got %v", found)
+	}
+
+	ch, err = d.FindPeers(context.Background(), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = found[:0]
+	for pi := range ch {
+		found = append(found, pi)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected both peers without a filter, got %v", found)
+	}
+}
+
+func TestEffectiveTTL(t *testing.T) {
+	for _, tc := range []struct {
+		requested, min, max, expected time.Duration
+	}{
+		{0, time.Minute, time.Hour, time.Hour},
+		{time.Second, time.Minute, time.Hour, time.Minute},
+		{2 * time.Hour, time.Minute, time.Hour, time.Hour},
+		{30 * time.Minute, time.Minute, time.Hour, 30 * time.Minute},
+		{2 * time.Hour, time.Minute, 0, 2 * time.Hour},
+		{0, time.Minute, 0, time.Minute},
+	} {
+		if ttl := EffectiveTTL(tc.requested, tc.min, tc.max); ttl != tc.expected {
+			t.Errorf("EffectiveTTL(%s, %s, %s): expected %s, got %s", tc.requested, tc.min, tc.max, tc.expected, ttl)
+		}
+	}
+}
+
+func TestBackoffOption(t *testing.T) {
+	var opts Options
+	if err := opts.Apply(TTL(time.Hour), Backoff(time.Second, time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if opts.BackoffMin != time.Second || opts.BackoffMax != time.Minute || opts.Ttl != time.Hour {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+
+	for _, b := range [][2]time.Duration{{0, time.Minute}, {-time.Second, time.Minute}, {time.Minute, time.Second}} {
+		if err := opts.Apply(Backoff(b[0], b[1])); err != ErrInvalidBackoff {
+			t.Fatalf("Backoff(%s, %s): expected ErrInvalidBackoff, got %v", b[0], b[1], err)
+		}
+	}
+}
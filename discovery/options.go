@@ -1,6 +1,11 @@
 package discovery
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
 
 // DiscoveryOpt is a single discovery option.
 type Option func(opts *Options) error
@@ -10,6 +15,16 @@ type Options struct {
 	Ttl   time.Duration
 	Limit int
 
+	// Filter, if set, selects the peers that should be returned by
+	// FindPeers. See the Filter option.
+	Filter func(peer.AddrInfo) bool
+
+	// BackoffMin and BackoffMax, if set, are the requested bounds of the
+	// delay between consecutive queries to the backend. See the Backoff
+	// option.
+	BackoffMin time.Duration
+	BackoffMax time.Duration
+
 	// Other (implementation-specific) options
 	Other map[interface{}]interface{}
 }
@@ -39,3 +54,44 @@ func Limit(limit int) Option {
 		return nil
 	}
 }
+
+// Filter is an option restricting the peers returned by FindPeers to the ones
+// for which filter returns true.
+//
+// Discoverers should honor it, either by filtering on the server side when
+// the backend supports it, or by wrapping their result channel with
+// FilterPeers. Filtered out peers shouldn't count towards the Limit: as
+// FilterPeers filters the peers after the backend applied the Limit,
+// discoverers using it must over-fetch (e.g. query the backend without a
+// limit) and stop once Limit peers passed the filter. Otherwise, a filtered
+// query may return fewer than Limit peers.
+func Filter(filter func(peer.AddrInfo) bool) Option {
+	return func(opts *Options) error {
+		opts.Filter = filter
+		return nil
+	}
+}
+
+// ErrInvalidBackoff is returned by the Backoff option when its bounds are
+// invalid.
+var ErrInvalidBackoff = errors.New("backoff bounds must satisfy 0 < min <= max")
+
+// Backoff is an option requesting that the delay between consecutive queries
+// to the backend (e.g. when re-advertising, or when polling for peers) stays
+// within [min, max], backing off exponentially between these bounds, e.g.
+// after failures or when no new peers are found.
+//
+// Backoff is a request: implementations that don't support it ignore it. It
+// doesn't override TTL: when re-advertising, Advertisers should wait at least
+// the backoff delay, but no longer than the effective TTL of the previous
+// advertisement, to avoid letting it lapse.
+func Backoff(min, max time.Duration) Option {
+	return func(opts *Options) error {
+		if min <= 0 || max < min {
+			return ErrInvalidBackoff
+		}
+		opts.BackoffMin = min
+		opts.BackoffMax = max
+		return nil
+	}
+}
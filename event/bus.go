@@ -1,16 +1,80 @@
 package event
 
 import (
+	"errors"
 	"io"
 	"reflect"
 )
 
+// ErrOptionNotSupported is returned by the generic subscriber and emitter
+// options defined in this package when the event bus implementation doesn't
+// support them.
+var ErrOptionNotSupported = errors.New("option not supported by this event bus implementation")
+
 // SubscriptionOpt represents a subscriber option. Use the options exposed by the implementation of choice.
 type SubscriptionOpt = func(interface{}) error
 
 // EmitterOpt represents an emitter option. Use the options exposed by the implementation of choice.
 type EmitterOpt = func(interface{}) error
 
+// StatefulSettings is implemented by the emitter settings of event bus
+// implementations that support the Stateful emitter option.
+type StatefulSettings interface {
+	// SetStateful makes the emitter remember the last event it emitted.
+	SetStateful()
+}
+
+// Stateful is an emitter option which makes the emitter remember the last
+// event it emitted. A new subscriber to the event type receives that event
+// once, immediately after subscribing, followed by all subsequent events.
+// If no event has ever been emitted, new subscribers receive nothing until
+// the next call to Emit.
+//
+// This is useful for events describing state, such as
+// EvtLocalAddressesUpdated, which subscribers need to know about even when
+// they subscribe after it was emitted.
+//
+// Stateful returns ErrOptionNotSupported if the event bus implementation's
+// emitter settings don't implement StatefulSettings.
+func Stateful(settings interface{}) error {
+	s, ok := settings.(StatefulSettings)
+	if !ok {
+		return ErrOptionNotSupported
+	}
+	s.SetStateful()
+	return nil
+}
+
+var _ EmitterOpt = Stateful
+
+// EmitSyncSettings is implemented by the emitter settings of event bus
+// implementations that support the EmitSync emitter option.
+type EmitSyncSettings interface {
+	// SetEmitSync makes Emit wait for the event to be delivered.
+	SetEmitSync()
+}
+
+// EmitSync is an emitter option which makes Emit return only once the event
+// has been delivered into the channels of all the subscriptions that existed
+// when Emit was called. This makes it possible to deterministically emit an
+// event and then observe its effects, e.g. in tests.
+//
+// WARNING: a subscriber that never drains its channel will make Emit block
+// forever. Only use this option when all subscribers are known to keep up.
+//
+// EmitSync returns ErrOptionNotSupported if the event bus implementation's
+// emitter settings don't implement EmitSyncSettings.
+func EmitSync(settings interface{}) error {
+	s, ok := settings.(EmitSyncSettings)
+	if !ok {
+		return ErrOptionNotSupported
+	}
+	s.SetEmitSync()
+	return nil
+}
+
+var _ EmitterOpt = EmitSync
+
 // CancelFunc closes a subscriber.
 type CancelFunc = func()
 
@@ -20,8 +84,37 @@ type wildcardSubscriptionType interface{}
 
 // WildcardSubscription is the type to subscribe to to receive all events
 // emitted in the eventbus.
+//
+// A wildcard subscriber receives every event emitted on the bus, so it must
+// drain its channel promptly: as with any subscription, a subscriber that
+// falls behind makes emitters block, and events buffered in its channel are
+// retained until they are read. Closing the subscription unregisters it.
 var WildcardSubscription = new(wildcardSubscriptionType)
 
+// ErrNoMatchingEventTypes is returned by SubscribeFiltered when none of the
+// event types known to the bus match the filter.
+var ErrNoMatchingEventTypes = errors.New("no event types match the filter")
+
+// SubscribeFiltered subscribes to all the event types known to the bus (see
+// Bus.GetAllEventTypes) for which filter returns true, under a single
+// subscription. filter is called with value types.
+//
+// Only the event types known to the bus at the time of the call are taken
+// into account; use WildcardSubscription to receive events of types that
+// appear later.
+func SubscribeFiltered(bus Bus, filter func(reflect.Type) bool, opts ...SubscriptionOpt) (Subscription, error) {
+	var types []interface{}
+	for _, t := range bus.GetAllEventTypes() {
+		if filter(t) {
+			types = append(types, reflect.New(t).Interface())
+		}
+	}
+	if len(types) == 0 {
+		return nil, ErrNoMatchingEventTypes
+	}
+	return bus.Subscribe(types, opts...)
+}
+
 // Emitter represents an actor that emits events onto the eventbus.
 type Emitter interface {
 	io.Closer
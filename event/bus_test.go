@@ -0,0 +1,97 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testBus struct {
+	types      []reflect.Type
+	subscribed interface{}
+}
+
+func (b *testBus) Subscribe(eventType interface{}, opts ...SubscriptionOpt) (Subscription, error) {
+	b.subscribed = eventType
+	return nil, nil
+}
+
+func (b *testBus) Emitter(eventType interface{}, opts ...EmitterOpt) (Emitter, error) {
+	return nil, nil
+}
+
+func (b *testBus) GetAllEventTypes() []reflect.Type {
+	return b.types
+}
+
+func TestSubscribeFiltered(t *testing.T) {
+	bus := &testBus{types: []reflect.Type{
+		reflect.TypeOf(EvtLocalAddressesUpdated{}),
+		reflect.TypeOf(EvtPeerIdentificationCompleted{}),
+		reflect.TypeOf(EvtPeerIdentificationFailed{}),
+	}}
+
+	_, err := SubscribeFiltered(bus, func(t reflect.Type) bool {
+		return t.Name() != "EvtLocalAddressesUpdated"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, ok := bus.subscribed.([]interface{})
+	if !ok || len(types) != 2 {
+		t.Fatalf("expected to subscribe to 2 event types, got %v", bus.subscribed)
+	}
+	if _, ok := types[0].(*EvtPeerIdentificationCompleted); !ok {
+		t.Fatalf("expected a *EvtPeerIdentificationCompleted, got %T", types[0])
+	}
+	if _, ok := types[1].(*EvtPeerIdentificationFailed); !ok {
+		t.Fatalf("expected a *EvtPeerIdentificationFailed, got %T", types[1])
+	}
+
+	_, err = SubscribeFiltered(bus, func(reflect.Type) bool { return false })
+	if err != ErrNoMatchingEventTypes {
+		t.Fatalf("expected ErrNoMatchingEventTypes, got %v", err)
+	}
+}
+
+// emitterSettings are the emitter settings of a fake event bus supporting
+// the generic emitter options.
+type emitterSettings struct {
+	stateful bool
+	emitSync bool
+}
+
+func (s *emitterSettings) SetStateful() { s.stateful = true }
+func (s *emitterSettings) SetEmitSync() { s.emitSync = true }
+
+// plainSettings are the emitter settings of a fake event bus supporting none
+// of the generic emitter options.
+type plainSettings struct{}
+
+func TestEmitSync(t *testing.T) {
+	var s emitterSettings
+	if err := EmitSync(&s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.emitSync || s.stateful {
+		t.Fatalf("expected only EmitSync to be set, got %+v", s)
+	}
+	if err := EmitSync(&plainSettings{}); err != ErrOptionNotSupported {
+		t.Fatalf("expected ErrOptionNotSupported, got %v", err)
+	}
+}
+
+func TestStateful(t *testing.T) {
+	var s emitterSettings
+	if err := Stateful(&s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.stateful || s.emitSync {
+		t.Fatalf("expected only Stateful to be set, got %+v", s)
+	}
+	if err := Stateful(&plainSettings{}); err != ErrOptionNotSupported {
+		t.Fatalf("expected ErrOptionNotSupported, got %v", err)
+	}
+	if err := Stateful(nil); err != ErrOptionNotSupported {
+		t.Fatalf("expected ErrOptionNotSupported for nil settings, got %v", err)
+	}
+}
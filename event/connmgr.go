@@ -0,0 +1,23 @@
+package event
+
+// EvtConnManagerTrim should be emitted by connection managers after trimming
+// connections, i.e. when a call to ConnManager.TrimOpenConns (explicit, or
+// triggered by the manager itself) closed connections. It isn't emitted when
+// a trim closes no connection.
+//
+// It lets consumers tell connections dropped because of connection manager
+// pressure apart from those dropped because of network faults.
+type EvtConnManagerTrim struct {
+	// Trimmed is the number of connections closed by the trim.
+	Trimmed int
+	// Reason is a human-readable explanation of why the trim happened, e.g.
+	// "high watermark exceeded" or "explicit trim".
+	Reason string
+
+	// LowWater is the number of connections the manager trims down to.
+	LowWater int
+	// HighWater is the number of connections above which the manager trims.
+	HighWater int
+	// ConnCount is the number of connections before the trim.
+	ConnCount int
+}
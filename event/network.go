@@ -47,6 +47,12 @@ import (
 // Explanation: There were two connections and one was cut. This connection
 // might have been in active use but neither peer will observe a change in
 // "connectedness". Peers should always make sure to re-try network requests.
+//
+// Unlike network.Notifiee, which reports every connection, this event is
+// de-bounced at the peer level, and can be consumed without managing a
+// notifiee's lifecycle:
+//
+//   sub, err := bus.Subscribe(new(event.EvtPeerConnectednessChanged))
 type EvtPeerConnectednessChanged struct {
 	// Peer is the remote peer who's connectedness has changed.
 	Peer peer.ID
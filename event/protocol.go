@@ -18,6 +18,11 @@ type EvtPeerProtocolsUpdated struct {
 // EvtLocalProtocolsUpdated should be emitted when stream handlers are attached or detached from the local host.
 // For handlers attached with a matcher predicate (host.SetStreamHandlerMatch()), only the protocol ID will be
 // included in this event.
+//
+// It mirrors EvtLocalAddressesUpdated for the local protocol set: components advertising the protocols supported
+// by the host (e.g. discovery or identify push) can subscribe to it to re-advertise promptly. It should only be
+// emitted when the protocol set actually changes, i.e. replacing the handler of an already registered protocol
+// doesn't emit it, and a protocol never appears in both Added and Removed.
 type EvtLocalProtocolsUpdated struct {
 	// Added enumerates the protocols that were added locally.
 	Added []protocol.ID
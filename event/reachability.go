@@ -7,7 +7,13 @@ import (
 // EvtLocalReachabilityChanged is an event struct to be emitted when the local's
 // node reachability changes state.
 //
-// This event is usually emitted by the AutoNAT subsystem.
+// This event is usually emitted by the AutoNAT subsystem, and consumed by
+// subsystems whose behavior depends on whether the node is publicly
+// reachable, e.g. relay clients which only need to reserve slots on relays
+// when the node is private.
 type EvtLocalReachabilityChanged struct {
+	// Reachability is the new reachability of the local node. It is
+	// network.ReachabilityUnknown until the emitter has come to a
+	// conclusion.
 	Reachability network.Reachability
 }
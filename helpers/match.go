@@ -12,7 +12,10 @@ import (
 // matches the base protocol. A given protocol ID matches the base protocol if
 // the IDs are the same and if the semantic version of the base protocol is the
 // same or higher than that of the protocol ID provided.
-// TODO
+//
+// Deprecated: use protocol.SemverMatcher, which parses versions the same way
+// but matches them against an explicit constraint (e.g. "^1.2.0"), rather
+// than accepting the same major version with an older or equal minor version.
 func MultistreamSemverMatcher(base protocol.ID) (func(string) bool, error) {
 	parts := strings.Split(string(base), "/")
 	vers, err := semver.NewVersion(parts[len(parts)-1])
@@ -1,6 +1,21 @@
 package host
 
-import "github.com/libp2p/go-libp2p-core/peer"
+import (
+	"context"
+	"errors"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/record"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/libp2p/go-msgio"
+)
+
+// ErrNoPrivateKey is returned by SignedPeerRecord when the host's private key
+// can't be found in its peerstore.
+var ErrNoPrivateKey = errors.New("host private key not found in peerstore")
 
 // InfoFromHost returns a peer.AddrInfo struct with the Host's ID and all of its Addrs.
 func InfoFromHost(h Host) *peer.AddrInfo {
@@ -9,3 +24,92 @@ func InfoFromHost(h Host) *peer.AddrInfo {
 		Addrs: h.Addrs(),
 	}
 }
+
+// NewStreamWithResult opens a new stream to p, like Host.NewStream, and also
+// returns the protocol that was selected among pids. Protocols are tried in
+// argument order; if p supports none of them, the returned error wraps
+// protocol.ErrNoSupportedProtocols.
+func NewStreamWithResult(ctx context.Context, h Host, p peer.ID, pids ...protocol.ID) (network.Stream, protocol.ID, error) {
+	s, err := h.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, "", err
+	}
+	return s, s.Protocol(), nil
+}
+
+// SignedPeerRecord builds a peer.PeerRecord containing the host's current
+// addresses and returns it sealed in an Envelope signed with the host's
+// private key, as found in its peerstore.
+//
+// The envelope uses the standard peer record domain and payload type (see
+// peer.PeerRecordEnvelopeDomain and peer.PeerRecordEnvelopePayloadType).
+func SignedPeerRecord(h Host) (*record.Envelope, error) {
+	priv := h.Peerstore().PrivKey(h.ID())
+	if priv == nil {
+		return nil, ErrNoPrivateKey
+	}
+	rec := peer.PeerRecordFromAddrInfo(*InfoFromHost(h))
+	return record.Seal(rec, priv)
+}
+
+// SendRequest opens a new stream to p for the given protocol, writes req as
+// a varint length-prefixed protobuf message, closes the stream for writing,
+// and reads a length-prefixed protobuf response into resp. Messages larger
+// than network.MessageSizeMax are rejected.
+//
+// The deadline of ctx, if any, applies to the whole exchange. If ctx is
+// cancelled, or if anything fails, the stream is reset rather than left
+// open; otherwise, it is closed once the response has been read.
+func SendRequest(ctx context.Context, h Host, p peer.ID, pid protocol.ID, req, resp proto.Message) error {
+	s, err := h.NewStream(ctx, p, pid)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := s.SetDeadline(deadline); err != nil {
+			s.Reset()
+			return err
+		}
+	}
+
+	// Unblock reads and writes if ctx is cancelled mid-exchange.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Reset()
+		case <-done:
+		}
+	}()
+
+	if err := exchange(s, req, resp); err != nil {
+		s.Reset()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return s.Close()
+}
+
+func exchange(s network.Stream, req, resp proto.Message) error {
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if err := msgio.NewVarintWriter(s).WriteMsg(b); err != nil {
+		return err
+	}
+	if err := s.CloseWrite(); err != nil {
+		return err
+	}
+
+	r := msgio.NewVarintReaderSize(s, network.MessageSizeMax)
+	msg, err := r.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer r.ReleaseMsg(msg)
+	return proto.Unmarshal(msg, resp)
+}
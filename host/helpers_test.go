@@ -0,0 +1,109 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/libp2p/go-msgio"
+)
+
+var errStreamReset = errors.New("stream reset")
+
+// pipeStream is the local end of a stream made of two pipes.
+type pipeStream struct {
+	network.Stream
+	r *io.PipeReader
+	w *io.PipeWriter
+
+	reset, closed int32
+}
+
+func (s *pipeStream) Read(b []byte) (int, error)  { return s.r.Read(b) }
+func (s *pipeStream) Write(b []byte) (int, error) { return s.w.Write(b) }
+func (s *pipeStream) CloseWrite() error           { return s.w.Close() }
+func (s *pipeStream) SetDeadline(time.Time) error { return nil }
+func (s *pipeStream) Close() error                { atomic.StoreInt32(&s.closed, 1); return s.w.Close() }
+func (s *pipeStream) Reset() error {
+	atomic.StoreInt32(&s.reset, 1)
+	s.r.CloseWithError(errStreamReset)
+	s.w.CloseWithError(errStreamReset)
+	return nil
+}
+
+type testHost struct {
+	Host
+	handler func(r io.Reader, w io.WriteCloser)
+	stream  *pipeStream
+}
+
+func (h *testHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	localR, remoteW := io.Pipe()
+	remoteR, localW := io.Pipe()
+	h.stream = &pipeStream{r: localR, w: localW}
+	go h.handler(remoteR, remoteW)
+	return h.stream, nil
+}
+
+func TestSendRequest(t *testing.T) {
+	h := &testHost{handler: func(r io.Reader, w io.WriteCloser) {
+		defer w.Close()
+		msg, err := msgio.NewVarintReaderSize(r, network.MessageSizeMax).ReadMsg()
+		if err != nil {
+			return
+		}
+		var req pb.PublicKey
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return
+		}
+		// Respond with the key data reversed.
+		data := make([]byte, len(req.Data))
+		for i, b := range req.Data {
+			data[len(data)-1-i] = b
+		}
+		b, _ := proto.Marshal(&pb.PublicKey{Type: req.Type, Data: data})
+		msgio.NewVarintWriter(w).WriteMsg(b)
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var resp pb.PublicKey
+	err := SendRequest(ctx, h, "peer", "/test/1.0.0", &pb.PublicKey{Type: pb.KeyType_Ed25519, Data: []byte("abc")}, &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Type != pb.KeyType_Ed25519 || string(resp.Data) != "cba" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if atomic.LoadInt32(&h.stream.closed) != 1 || atomic.LoadInt32(&h.stream.reset) != 0 {
+		t.Fatal("expected the stream to be closed")
+	}
+}
+
+func TestSendRequestCancel(t *testing.T) {
+	h := &testHost{handler: func(r io.Reader, w io.WriteCloser) {
+		// Read the request, but never respond.
+		io.Copy(ioutil.Discard, r)
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	var resp pb.PublicKey
+	err := SendRequest(ctx, h, "peer", "/test/1.0.0", &pb.PublicKey{}, &resp)
+	if err != context.Canceled {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+	if atomic.LoadInt32(&h.stream.reset) != 1 {
+		t.Fatal("expected the stream to be reset")
+	}
+}
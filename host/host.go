@@ -62,6 +62,12 @@ type Host interface {
 	// NewStream opens a new stream to given peer p, and writes a p2p/protocol
 	// header with given ProtocolID. If there is no connection to p, attempts
 	// to create one. If ProtocolID is "", writes no header.
+	//
+	// When several protocol IDs are given, they are tried in argument order,
+	// i.e. in decreasing order of preference, and the first one supported by
+	// the peer is selected. The selected protocol is returned by the stream's
+	// Protocol method. If the peer supports none of them, the returned error
+	// wraps protocol.ErrNoSupportedProtocols.
 	// (Threadsafe)
 	NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error)
 
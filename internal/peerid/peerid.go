@@ -0,0 +1,32 @@
+// Package peerid derives peer IDs from public keys. It is shared by the peer
+// and record packages, as the record package can't import the peer package.
+package peerid
+
+import (
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	mh "github.com/multiformats/go-multihash"
+)
+
+// MaxInlineKeyLength is the maximum length of the public keys inlined in
+// peer IDs (using the "identity" multihash function).
+const MaxInlineKeyLength = 42
+
+// InliningEnabled reports whether short public keys are inlined in peer IDs.
+// The peer package points it at peer.AdvancedEnableInlining; if the peer
+// package isn't linked in, the flag can't have been changed from its default,
+// which is true.
+var InliningEnabled = func() bool { return true }
+
+// FromPublicKey returns the multihash bytes of the peer ID of pk, as
+// peer.IDFromPublicKey does.
+func FromPublicKey(pk ic.PubKey) ([]byte, error) {
+	b, err := pk.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var alg uint64 = mh.SHA2_256
+	if InliningEnabled() && len(b) <= MaxInlineKeyLength {
+		alg = mh.IDENTITY
+	}
+	return mh.Sum(b, alg, -1)
+}
@@ -0,0 +1,83 @@
+package introspection
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/introspection/pb"
+	"github.com/libp2p/go-libp2p-core/network"
+)
+
+// SnapshotNetwork returns the current state of the connections of n, and of
+// their streams, following the introspection schema.
+//
+// Only what's observable through the network interfaces is populated: the
+// identifiers, peer, endpoints, role and open timestamp of connections, and
+// the identifiers, protocol, role and open timestamp of streams. As closed
+// connections and streams aren't listed by the network, they're all
+// reported as active.
+//
+// Experimental.
+func SnapshotNetwork(n network.Network) (*pb.State, error) {
+	start := time.Now()
+	conns := n.Conns()
+	state := &pb.State{
+		Subsystems: &pb.Subsystems{
+			Connections: make([]*pb.Connection, 0, len(conns)),
+		},
+		InstantTs: timestamp(start),
+	}
+	for _, c := range conns {
+		state.Subsystems.Connections = append(state.Subsystems.Connections, snapshotConn(c))
+	}
+	state.SnapshotDurationMs = uint32(time.Since(start) / time.Millisecond)
+	return state, nil
+}
+
+func snapshotConn(c network.Conn) *pb.Connection {
+	stat := c.Stat()
+	conn := &pb.Connection{
+		Id:     []byte(c.ID()),
+		PeerId: c.RemotePeer().Pretty(),
+		Status: pb.Status_ACTIVE,
+		Endpoints: &pb.EndpointPair{
+			SrcMultiaddr: c.LocalMultiaddr().String(),
+			DstMultiaddr: c.RemoteMultiaddr().String(),
+		},
+		Timeline: &pb.Connection_Timeline{OpenTs: timestamp(stat.Opened)},
+		Role:     role(stat.Direction),
+	}
+
+	streams := c.GetStreams()
+	conn.Streams = &pb.StreamList{Streams: make([]*pb.Stream, 0, len(streams))}
+	for _, s := range streams {
+		sstat := s.Stat()
+		conn.Streams.Streams = append(conn.Streams.Streams, &pb.Stream{
+			Id:       []byte(s.ID()),
+			Protocol: string(s.Protocol()),
+			Role:     role(sstat.Direction),
+			Conn: &pb.Stream_ConnectionRef{
+				Connection: &pb.Stream_ConnectionRef_ConnId{ConnId: conn.Id},
+			},
+			Timeline: &pb.Stream_Timeline{OpenTs: timestamp(sstat.Opened)},
+			Status:   pb.Status_ACTIVE,
+		})
+	}
+	return conn
+}
+
+// role returns the introspection role matching a direction. As the schema
+// has no unknown role, an unknown direction is reported as a responder.
+func role(dir network.Direction) pb.Role {
+	if dir == network.DirOutbound {
+		return pb.Role_INITIATOR
+	}
+	return pb.Role_RESPONDER
+}
+
+// timestamp returns t in milliseconds since the epoch, or 0 if t is unset.
+func timestamp(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano() / int64(time.Millisecond))
+}
@@ -0,0 +1,94 @@
+package introspection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/introspection/pb"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+type testNetwork struct {
+	network.Network
+	conns []network.Conn
+}
+
+func (n *testNetwork) Conns() []network.Conn { return n.conns }
+
+type testConn struct {
+	network.Conn
+	id      string
+	remote  peer.ID
+	stat    network.Stat
+	streams []network.Stream
+}
+
+func (c *testConn) ID() string                    { return c.id }
+func (c *testConn) RemotePeer() peer.ID           { return c.remote }
+func (c *testConn) LocalMultiaddr() ma.Multiaddr  { return ma.StringCast("/ip4/127.0.0.1/tcp/1") }
+func (c *testConn) RemoteMultiaddr() ma.Multiaddr { return ma.StringCast("/ip4/1.2.3.4/tcp/2") }
+func (c *testConn) Stat() network.Stat            { return c.stat }
+func (c *testConn) GetStreams() []network.Stream  { return c.streams }
+
+type testStream struct {
+	network.Stream
+	id    string
+	proto protocol.ID
+	stat  network.Stat
+}
+
+func (s *testStream) ID() string            { return s.id }
+func (s *testStream) Protocol() protocol.ID { return s.proto }
+func (s *testStream) Stat() network.Stat    { return s.stat }
+
+func TestSnapshotNetwork(t *testing.T) {
+	opened := time.Unix(1600000000, 0)
+	remote := peer.ID("remote")
+	n := &testNetwork{conns: []network.Conn{
+		&testConn{
+			id:     "c1",
+			remote: remote,
+			stat:   network.Stat{Direction: network.DirOutbound, Opened: opened},
+			streams: []network.Stream{&testStream{
+				id:    "s1",
+				proto: "/echo/1.0.0",
+				stat:  network.Stat{Direction: network.DirInbound},
+			}},
+		},
+	}}
+
+	state, err := SnapshotNetwork(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := state.Subsystems.Connections
+	if len(conns) != 1 {
+		t.Fatalf("expected 1 connection, got %d", len(conns))
+	}
+	c := conns[0]
+	if string(c.Id) != "c1" || c.PeerId != remote.Pretty() || c.Role != pb.Role_INITIATOR || c.Status != pb.Status_ACTIVE {
+		t.Fatalf("unexpected connection %v", c)
+	}
+	if c.Endpoints.SrcMultiaddr != "/ip4/127.0.0.1/tcp/1" || c.Endpoints.DstMultiaddr != "/ip4/1.2.3.4/tcp/2" {
+		t.Fatalf("unexpected endpoints %v", c.Endpoints)
+	}
+	if c.Timeline.OpenTs != 1600000000000 {
+		t.Fatalf("expected the open timestamp in ms, got %d", c.Timeline.OpenTs)
+	}
+
+	streams := c.Streams.Streams
+	if len(streams) != 1 {
+		t.Fatalf("expected 1 stream, got %d", len(streams))
+	}
+	s := streams[0]
+	if string(s.Id) != "s1" || s.Protocol != "/echo/1.0.0" || s.Role != pb.Role_RESPONDER || s.Timeline.OpenTs != 0 {
+		t.Fatalf("unexpected stream %v", s)
+	}
+	if ref, ok := s.Conn.Connection.(*pb.Stream_ConnectionRef_ConnId); !ok || string(ref.ConnId) != "c1" {
+		t.Fatalf("expected the stream to reference its connection, got %v", s.Conn)
+	}
+}
@@ -14,6 +14,10 @@ import (
 // Metrics are available for total bandwidth across all peers / protocols, as well
 // as segmented by remote peer ID and protocol ID.
 type BandwidthCounter struct {
+	// Accessed atomically, keep first for 64-bit alignment.
+	sentSizes sizeHistogram
+	recvSizes sizeHistogram
+
 	totalIn  flow.Meter
 	totalOut flow.Meter
 
@@ -33,12 +37,14 @@ func NewBandwidthCounter() *BandwidthCounter {
 // without associating the bandwidth to a specific peer or protocol.
 func (bwc *BandwidthCounter) LogSentMessage(size int64) {
 	bwc.totalOut.Mark(uint64(size))
+	bwc.sentSizes.observe(size)
 }
 
 // LogRecvMessage records the size of an incoming message
 // without associating the bandwith to a specific peer or protocol.
 func (bwc *BandwidthCounter) LogRecvMessage(size int64) {
 	bwc.totalIn.Mark(uint64(size))
+	bwc.recvSizes.observe(size)
 }
 
 // LogSentMessageStream records the size of an outgoing message over a single logical stream.
@@ -155,11 +161,23 @@ func (bwc *BandwidthCounter) GetBandwidthByProtocol() map[protocol.ID]Stats {
 	return protocols
 }
 
+// Histogram returns the distribution of the sizes of the messages logged
+// with LogSentMessage and LogRecvMessage.
+func (bwc *BandwidthCounter) Histogram() Histogram {
+	return Histogram{
+		Sent: bwc.sentSizes.snapshot(),
+		Recv: bwc.recvSizes.snapshot(),
+	}
+}
+
 // Reset clears all stats.
 func (bwc *BandwidthCounter) Reset() {
 	bwc.totalIn.Reset()
 	bwc.totalOut.Reset()
 
+	bwc.sentSizes.reset()
+	bwc.recvSizes.reset()
+
 	bwc.protocolIn.Clear()
 	bwc.protocolOut.Clear()
 
@@ -167,6 +185,26 @@ func (bwc *BandwidthCounter) Reset() {
 	bwc.peerOut.Clear()
 }
 
+// ResetProtocol clears the stats of the given protocol, leaving the stats of
+// other protocols, of peers and the totals intact.
+//
+// It is safe to call concurrently with the Log* methods; messages logged
+// concurrently with the reset may or may not be accounted for.
+func (bwc *BandwidthCounter) ResetProtocol(proto protocol.ID) {
+	bwc.protocolIn.Remove(string(proto))
+	bwc.protocolOut.Remove(string(proto))
+}
+
+// ResetPeer clears the stats of the given peer, leaving the stats of other
+// peers, of protocols and the totals intact.
+//
+// It is safe to call concurrently with the Log* methods; messages logged
+// concurrently with the reset may or may not be accounted for.
+func (bwc *BandwidthCounter) ResetPeer(p peer.ID) {
+	bwc.peerIn.Remove(string(p))
+	bwc.peerOut.Remove(string(p))
+}
+
 // TrimIdle trims all timers idle since the given time.
 func (bwc *BandwidthCounter) TrimIdle(since time.Time) {
 	bwc.peerIn.TrimIdle(since)
@@ -229,3 +229,72 @@ func assertApproxEq(t *testing.T, expected, actual float64) {
 		t.Errorf("expected %f (±%f), got %f", expected, margin, actual)
 	}
 }
+
+func TestResetProtocolAndPeer(t *testing.T) {
+	bwc := NewBandwidthCounter()
+
+	p0, p1 := peer.ID("peer-0"), peer.ID("peer-1")
+	proto0, proto1 := protocol.ID("proto-0"), protocol.ID("proto-1")
+
+	bwc.LogSentMessage(100)
+	bwc.LogSentMessageStream(100, proto0, p0)
+	bwc.LogSentMessageStream(100, proto1, p1)
+
+	// Wait for the meters to be updated.
+	time.Sleep(1*time.Second + time.Millisecond)
+
+	bwc.ResetProtocol(proto0)
+	if _, ok := bwc.GetBandwidthByProtocol()[proto0]; ok {
+		t.Error("expected the protocol to be reset")
+	}
+	assertEq(t, 100, bwc.GetBandwidthForProtocol(proto1).TotalOut)
+	assertEq(t, 100, bwc.GetBandwidthForPeer(p0).TotalOut)
+
+	bwc.ResetPeer(p0)
+	if _, ok := bwc.GetBandwidthByPeer()[p0]; ok {
+		t.Error("expected the peer to be reset")
+	}
+	assertEq(t, 100, bwc.GetBandwidthForPeer(p1).TotalOut)
+	assertEq(t, 100, bwc.GetBandwidthTotals().TotalOut)
+}
+
+func TestHistogram(t *testing.T) {
+	bwc := NewBandwidthCounter()
+
+	for _, size := range []int64{0, 1, 2, 3, 4, 1000, 1024, math.MaxInt64} {
+		bwc.LogSentMessage(size)
+	}
+	bwc.LogRecvMessage(100)
+
+	h := bwc.Histogram()
+	expected := map[int64]uint64{
+		0:             1, // 0
+		1:             1, // 1
+		3:             2, // 2, 3
+		7:             1, // 4
+		1023:          1, // 1000
+		2047:          1, // 1024
+		math.MaxInt64: 1, // math.MaxInt64
+	}
+	for _, b := range h.Sent {
+		if b.Count != expected[b.UpperBound] {
+			t.Errorf("expected %d messages of size <= %d, got %d", expected[b.UpperBound], b.UpperBound, b.Count)
+		}
+	}
+	for _, b := range h.Recv {
+		exp := uint64(0)
+		if b.UpperBound == 127 {
+			exp = 1
+		}
+		if b.Count != exp {
+			t.Errorf("expected %d received messages of size <= %d, got %d", exp, b.UpperBound, b.Count)
+		}
+	}
+
+	bwc.Reset()
+	for _, b := range bwc.Histogram().Sent {
+		if b.Count != 0 {
+			t.Fatal("expected the histogram to be reset")
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// histogramBuckets is the number of buckets of message size histograms.
+// Bucket i counts the messages of size s with 2^(i-1) <= s < 2^i (bucket 0
+// counts empty messages), and the last bucket counts all larger messages.
+const histogramBuckets = 33
+
+// sizeHistogram counts message sizes in power-of-two buckets. It is updated
+// atomically, and must be 64-bit aligned.
+type sizeHistogram [histogramBuckets]uint64
+
+func (h *sizeHistogram) observe(size int64) {
+	if size < 0 {
+		return
+	}
+	i := bits.Len64(uint64(size))
+	if i >= histogramBuckets {
+		i = histogramBuckets - 1
+	}
+	atomic.AddUint64(&h[i], 1)
+}
+
+func (h *sizeHistogram) snapshot() []HistogramBucket {
+	buckets := make([]HistogramBucket, histogramBuckets)
+	for i := range buckets {
+		buckets[i].UpperBound = int64(1)<<uint(i) - 1
+		buckets[i].Count = atomic.LoadUint64(&h[i])
+	}
+	buckets[histogramBuckets-1].UpperBound = math.MaxInt64
+	return buckets
+}
+
+func (h *sizeHistogram) reset() {
+	for i := range h {
+		atomic.StoreUint64(&h[i], 0)
+	}
+}
+
+// HistogramBucket is a bucket of a message size histogram.
+type HistogramBucket struct {
+	// UpperBound is the (inclusive) maximum size of the messages counted in
+	// this bucket. The lower bound is the upper bound of the previous
+	// bucket, exclusive.
+	UpperBound int64
+	// Count is the number of messages counted in this bucket. Counts aren't
+	// cumulative.
+	Count uint64
+}
+
+// Histogram is a point-in-time snapshot of the distribution of the sizes of
+// the messages sent and received. Buckets have power-of-two bounds, and are
+// sorted by increasing upper bound.
+type Histogram struct {
+	Sent []HistogramBucket
+	Recv []HistogramBucket
+}
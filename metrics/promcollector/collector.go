@@ -0,0 +1,179 @@
+// Package promcollector exposes the bandwidth metrics of a metrics.Reporter
+// as Prometheus metrics.
+//
+// It lives in a separate package so that programs which only import the
+// metrics package don't link the Prometheus client library. The library is
+// still a requirement of this module, and thus appears in the module graph
+// of its users.
+package promcollector
+
+import (
+	"sort"
+
+	"github.com/libp2p/go-libp2p-core/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxPeers is the default maximum number of peers for which
+	// per-peer metrics are exported.
+	DefaultMaxPeers = 100
+	// DefaultMaxProtocols is the default maximum number of protocols for
+	// which per-protocol metrics are exported.
+	DefaultMaxProtocols = 100
+)
+
+var (
+	totalDesc = prometheus.NewDesc(
+		"libp2p_bandwidth_bytes_total",
+		"Total number of bytes transferred.",
+		[]string{"direction"}, nil,
+	)
+	rateDesc = prometheus.NewDesc(
+		"libp2p_bandwidth_rate_bytes_per_second",
+		"Current transfer rate, in bytes per second.",
+		[]string{"direction"}, nil,
+	)
+	protocolTotalDesc = prometheus.NewDesc(
+		"libp2p_bandwidth_protocol_bytes_total",
+		"Total number of bytes transferred, by protocol. Only the protocols with the most traffic are reported.",
+		[]string{"protocol", "direction"}, nil,
+	)
+	protocolRateDesc = prometheus.NewDesc(
+		"libp2p_bandwidth_protocol_rate_bytes_per_second",
+		"Current transfer rate, in bytes per second, by protocol. Only the protocols with the most traffic are reported.",
+		[]string{"protocol", "direction"}, nil,
+	)
+	peerTotalDesc = prometheus.NewDesc(
+		"libp2p_bandwidth_peer_bytes_total",
+		"Total number of bytes transferred, by peer. Only the peers with the most traffic are reported.",
+		[]string{"peer", "direction"}, nil,
+	)
+	peerRateDesc = prometheus.NewDesc(
+		"libp2p_bandwidth_peer_rate_bytes_per_second",
+		"Current transfer rate, in bytes per second, by peer. Only the peers with the most traffic are reported.",
+		[]string{"peer", "direction"}, nil,
+	)
+)
+
+// Collector is a prometheus.Collector exporting the bandwidth metrics of a
+// metrics.Reporter (usually a metrics.BandwidthCounter):
+//
+//   - libp2p_bandwidth_bytes_total (a counter) and
+//     libp2p_bandwidth_rate_bytes_per_second (a gauge), labeled by direction
+//     ("in" or "out").
+//   - libp2p_bandwidth_protocol_bytes_total and
+//     libp2p_bandwidth_protocol_rate_bytes_per_second, also labeled by
+//     protocol.
+//   - libp2p_bandwidth_peer_bytes_total and
+//     libp2p_bandwidth_peer_rate_bytes_per_second, also labeled by peer.
+//
+// To bound the cardinality of the per-protocol and per-peer metrics, they are
+// only exported for the protocols and peers with the most total traffic, up
+// to a maximum number of each (see WithMaxProtocols and WithMaxPeers).
+type Collector struct {
+	reporter     metrics.Reporter
+	maxPeers     int
+	maxProtocols int
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// Option is an option for NewCollector.
+type Option func(*Collector)
+
+// WithMaxPeers sets the maximum number of peers for which per-peer metrics
+// are exported. Zero disables per-peer metrics. It defaults to
+// DefaultMaxPeers.
+func WithMaxPeers(n int) Option {
+	return func(c *Collector) {
+		c.maxPeers = n
+	}
+}
+
+// WithMaxProtocols sets the maximum number of protocols for which
+// per-protocol metrics are exported. Zero disables per-protocol metrics. It
+// defaults to DefaultMaxProtocols.
+func WithMaxProtocols(n int) Option {
+	return func(c *Collector) {
+		c.maxProtocols = n
+	}
+}
+
+// NewCollector creates a Collector exporting the metrics of the given
+// Reporter:
+//
+//	prometheus.MustRegister(promcollector.NewCollector(bwc))
+func NewCollector(reporter metrics.Reporter, opts ...Option) *Collector {
+	c := &Collector{
+		reporter:     reporter,
+		maxPeers:     DefaultMaxPeers,
+		maxProtocols: DefaultMaxProtocols,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- totalDesc
+	ch <- rateDesc
+	ch <- protocolTotalDesc
+	ch <- protocolRateDesc
+	ch <- peerTotalDesc
+	ch <- peerRateDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	collectStats(ch, totalDesc, rateDesc, c.reporter.GetBandwidthTotals())
+
+	byProtocol := c.reporter.GetBandwidthByProtocol()
+	protos := make([]labeledStats, 0, len(byProtocol))
+	for proto, stats := range byProtocol {
+		protos = append(protos, labeledStats{string(proto), stats})
+	}
+	collectTop(ch, protocolTotalDesc, protocolRateDesc, protos, c.maxProtocols)
+
+	byPeer := c.reporter.GetBandwidthByPeer()
+	peers := make([]labeledStats, 0, len(byPeer))
+	for p, stats := range byPeer {
+		peers = append(peers, labeledStats{p.Pretty(), stats})
+	}
+	collectTop(ch, peerTotalDesc, peerRateDesc, peers, c.maxPeers)
+}
+
+type labeledStats struct {
+	label string
+	stats metrics.Stats
+}
+
+// collectTop collects the stats of the (at most) max entries with the most
+// total traffic.
+func collectTop(ch chan<- prometheus.Metric, totalDesc, rateDesc *prometheus.Desc, entries []labeledStats, max int) {
+	if max <= 0 {
+		return
+	}
+	if len(entries) > max {
+		sort.Slice(entries, func(i, j int) bool {
+			si, sj := entries[i].stats, entries[j].stats
+			return si.TotalIn+si.TotalOut > sj.TotalIn+sj.TotalOut
+		})
+		entries = entries[:max]
+	}
+	for _, e := range entries {
+		collectStats(ch, totalDesc, rateDesc, e.stats, e.label)
+	}
+}
+
+func collectStats(ch chan<- prometheus.Metric, totalDesc, rateDesc *prometheus.Desc, stats metrics.Stats, labels ...string) {
+	in := append(labels[:len(labels):len(labels)], "in")
+	out := append(labels[:len(labels):len(labels)], "out")
+	ch <- prometheus.MustNewConstMetric(totalDesc, prometheus.CounterValue, float64(stats.TotalIn), in...)
+	ch <- prometheus.MustNewConstMetric(totalDesc, prometheus.CounterValue, float64(stats.TotalOut), out...)
+	ch <- prometheus.MustNewConstMetric(rateDesc, prometheus.GaugeValue, stats.RateIn, in...)
+	ch <- prometheus.MustNewConstMetric(rateDesc, prometheus.GaugeValue, stats.RateOut, out...)
+}
@@ -0,0 +1,100 @@
+package promcollector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// staticReporter reports fixed stats.
+type staticReporter struct {
+	metrics.Reporter
+
+	totals     metrics.Stats
+	byPeer     map[peer.ID]metrics.Stats
+	byProtocol map[protocol.ID]metrics.Stats
+}
+
+func (r *staticReporter) GetBandwidthTotals() metrics.Stats { return r.totals }
+func (r *staticReporter) GetBandwidthByPeer() map[peer.ID]metrics.Stats {
+	return r.byPeer
+}
+func (r *staticReporter) GetBandwidthByProtocol() map[protocol.ID]metrics.Stats {
+	return r.byProtocol
+}
+
+func TestCollector(t *testing.T) {
+	r := &staticReporter{
+		totals: metrics.Stats{TotalIn: 600, TotalOut: 1200, RateIn: 6, RateOut: 12},
+		byPeer: make(map[peer.ID]metrics.Stats),
+		byProtocol: map[protocol.ID]metrics.Stats{
+			"/foo": {TotalIn: 500, TotalOut: 1000},
+			"/bar": {TotalIn: 100, TotalOut: 200},
+		},
+	}
+	for i := 1; i <= 3; i++ {
+		r.byPeer[peer.ID(fmt.Sprintf("peer-%d", i))] = metrics.Stats{TotalIn: int64(100 * i), TotalOut: int64(200 * i)}
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewCollector(r, WithMaxPeers(2), WithMaxProtocols(1)))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]float64)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			key := mf.GetName()
+			for _, l := range m.GetLabel() {
+				key += fmt.Sprintf(",%s=%s", l.GetName(), l.GetValue())
+			}
+			if mf.GetType() == dto.MetricType_COUNTER {
+				values[key] = m.GetCounter().GetValue()
+			} else {
+				values[key] = m.GetGauge().GetValue()
+			}
+		}
+	}
+
+	for key, expected := range map[string]float64{
+		"libp2p_bandwidth_bytes_total,direction=in":                                600,
+		"libp2p_bandwidth_bytes_total,direction=out":                               1200,
+		"libp2p_bandwidth_rate_bytes_per_second,direction=out":                     12,
+		"libp2p_bandwidth_protocol_bytes_total,direction=out,protocol=/foo":        1000,
+		"libp2p_bandwidth_peer_bytes_total,direction=in,peer=" + pretty("peer-3"):  300,
+		"libp2p_bandwidth_peer_bytes_total,direction=out,peer=" + pretty("peer-2"): 400,
+	} {
+		v, ok := values[key]
+		if !ok {
+			t.Errorf("missing metric %s", key)
+		} else if v != expected {
+			t.Errorf("expected %s to be %f, got %f", key, expected, v)
+		}
+	}
+
+	// Only the 2 peers and the protocol with the most traffic are exported.
+	if _, ok := values["libp2p_bandwidth_peer_bytes_total,direction=in,peer="+pretty("peer-1")]; ok {
+		t.Error("expected the peer with the least traffic not to be exported")
+	}
+	if _, ok := values["libp2p_bandwidth_protocol_bytes_total,direction=in,protocol=/bar"]; ok {
+		t.Error("expected the protocol with the least traffic not to be exported")
+	}
+
+	for _, mf := range families {
+		if mf.GetName() == "libp2p_bandwidth_bytes_total" && mf.GetType() != dto.MetricType_COUNTER {
+			t.Errorf("expected %s to be a counter, got %s", mf.GetName(), mf.GetType())
+		}
+	}
+}
+
+func pretty(p string) string {
+	return peer.ID(p).Pretty()
+}
@@ -0,0 +1,37 @@
+package mux
+
+// Caps describes the optional capabilities of a stream multiplexer.
+type Caps struct {
+	// SupportsHalfClose is true if CloseWrite and CloseRead are
+	// implemented as documented on MuxedStream, rather than e.g. by closing
+	// the whole stream.
+	SupportsHalfClose bool
+	// SupportsResetCode is true if streams can transmit an error code to
+	// the remote side when reset (see network.ResetWithError).
+	SupportsResetCode bool
+	// SupportsWritev is true if streams can write several buffers at once
+	// in a single frame.
+	SupportsWritev bool
+}
+
+// CapableMuxedConn is an optional interface implemented by MuxedConns that
+// can report the capabilities of their multiplexer.
+type CapableMuxedConn interface {
+	MuxedConn
+
+	// Capabilities returns the capabilities of the multiplexer.
+	Capabilities() Caps
+}
+
+// Capabilities returns the capabilities of the multiplexer of c, if it
+// implements CapableMuxedConn. Otherwise, no capability is reported.
+//
+// Wrappers of MuxedConns, such as upgraders, should implement
+// CapableMuxedConn by forwarding to this function, so that capabilities
+// propagate to higher layers.
+func Capabilities(c MuxedConn) Caps {
+	if cc, ok := c.(CapableMuxedConn); ok {
+		return cc.Capabilities()
+	}
+	return Caps{}
+}
@@ -40,6 +40,10 @@ type MuxedStream interface {
 	// CloseWrite closes the stream for writing but leaves it open for
 	// reading.
 	//
+	// Any buffered data is flushed and the remote side reads an EOF once it
+	// has consumed it. After CloseWrite, calls to Write fail, but reads
+	// continue to succeed until the remote side closes its end for writing.
+	//
 	// CloseWrite does not free the stream, users must still call Close or
 	// Reset.
 	CloseWrite() error
@@ -0,0 +1,178 @@
+package mux_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/mux"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+// bufPipe is a buffered, unidirectional pipe.
+type bufPipe struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	closed bool
+}
+
+func newBufPipe() *bufPipe {
+	p := &bufPipe{}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *bufPipe) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.buf.Len() == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if p.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return p.buf.Read(b)
+}
+
+func (p *bufPipe) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, errors.New("write on closed stream")
+	}
+	defer p.cond.Broadcast()
+	return p.buf.Write(b)
+}
+
+func (p *bufPipe) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+	return nil
+}
+
+// pipeStream is a minimal half-closable stream built on top of two bufPipes.
+type pipeStream struct {
+	r, w *bufPipe
+}
+
+var _ mux.MuxedStream = (*pipeStream)(nil)
+
+func newPipeStreams() (*pipeStream, *pipeStream) {
+	a, b := newBufPipe(), newBufPipe()
+	return &pipeStream{r: a, w: b}, &pipeStream{r: b, w: a}
+}
+
+func (s *pipeStream) Read(b []byte) (int, error)  { return s.r.Read(b) }
+func (s *pipeStream) Write(b []byte) (int, error) { return s.w.Write(b) }
+func (s *pipeStream) CloseWrite() error           { return s.w.Close() }
+func (s *pipeStream) CloseRead() error            { return nil }
+func (s *pipeStream) Reset() error                { return s.Close() }
+func (s *pipeStream) Close() error                { return s.CloseWrite() }
+
+func (s *pipeStream) SetDeadline(time.Time) error      { return nil }
+func (s *pipeStream) SetReadDeadline(time.Time) error  { return nil }
+func (s *pipeStream) SetWriteDeadline(time.Time) error { return nil }
+
+func TestSubtestHalfClose(t *testing.T) {
+	local, remote := newPipeStreams()
+	test.SubtestHalfClose(t, local, remote)
+}
+
+type testConn struct {
+	mux.MuxedConn
+}
+
+type capableConn struct {
+	testConn
+}
+
+func (capableConn) Capabilities() mux.Caps {
+	return mux.Caps{SupportsHalfClose: true, SupportsResetCode: true}
+}
+
+// upgradedConn wraps a MuxedConn, forwarding its capabilities.
+type upgradedConn struct {
+	mux.MuxedConn
+}
+
+func (c upgradedConn) Capabilities() mux.Caps {
+	return mux.Capabilities(c.MuxedConn)
+}
+
+func TestCapabilities(t *testing.T) {
+	if caps := mux.Capabilities(testConn{}); caps != (mux.Caps{}) {
+		t.Fatalf("expected no capabilities, got %+v", caps)
+	}
+
+	expected := mux.Caps{SupportsHalfClose: true, SupportsResetCode: true}
+	if caps := mux.Capabilities(capableConn{}); caps != expected {
+		t.Fatalf("expected %+v, got %+v", expected, caps)
+	}
+	if caps := mux.Capabilities(upgradedConn{capableConn{}}); caps != expected {
+		t.Fatalf("expected capabilities to propagate through wrappers, got %+v", caps)
+	}
+}
+
+// countingStream counts the writes (frames) it receives.
+type countingStream struct {
+	*pipeStream
+	writes int
+}
+
+func (s *countingStream) Write(b []byte) (int, error) {
+	s.writes++
+	return s.pipeStream.Write(b)
+}
+
+type writevStream struct {
+	*countingStream
+}
+
+func (s writevStream) Writev(bufs ...[]byte) (int, error) {
+	return s.Write(bytes.Join(bufs, nil))
+}
+
+func TestWritev(t *testing.T) {
+	header, body := []byte("header"), []byte("body")
+
+	local, remote := newPipeStreams()
+	s := &countingStream{pipeStream: local}
+	n, err := mux.Writev(s, header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(header)+len(body) || s.writes != 2 {
+		t.Fatalf("expected 2 sequential writes of %d bytes in total, got %d writes of %d bytes", len(header)+len(body), s.writes, n)
+	}
+
+	ws := writevStream{&countingStream{pipeStream: local}}
+	n, err = mux.Writev(ws, header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(header)+len(body) || ws.writes != 1 {
+		t.Fatalf("expected a single write of %d bytes, got %d writes of %d bytes", len(header)+len(body), ws.writes, n)
+	}
+
+	local.CloseWrite()
+	got, err := ioutil.ReadAll(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "headerbodyheaderbody" {
+		t.Fatalf("unexpected data %q", got)
+	}
+
+	// Errors are returned along with the number of bytes written so far.
+	n, err = mux.Writev(s, header, body)
+	if err == nil || n != 0 {
+		t.Fatalf("expected writing to a closed stream to fail, got %d, %v", n, err)
+	}
+}
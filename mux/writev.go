@@ -0,0 +1,33 @@
+package mux
+
+// WritevStream is an optional interface implemented by MuxedStreams that can
+// write several buffers at once, e.g. a header and a body, coalesced into a
+// single frame.
+type WritevStream interface {
+	MuxedStream
+
+	// Writev writes the contents of bufs, in order, as if they were
+	// concatenated. It returns the total number of bytes written across all
+	// buffers; if an error occurs, this is the number of bytes written
+	// before the error, which may end in the middle of a buffer.
+	Writev(bufs ...[]byte) (int, error)
+}
+
+// Writev writes the contents of bufs to s, in order. It uses s.Writev if s
+// implements WritevStream, and otherwise writes the buffers one after the
+// other. In both cases, the total number of bytes written across all
+// buffers is returned.
+func Writev(s MuxedStream, bufs ...[]byte) (int, error) {
+	if ws, ok := s.(WritevStream); ok {
+		return ws.Writev(bufs...)
+	}
+	var total int
+	for _, b := range bufs {
+		n, err := s.Write(b)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
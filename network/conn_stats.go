@@ -0,0 +1,94 @@
+package network
+
+import "sync/atomic"
+
+// ConnStreamCounter is an optional interface implemented by connections
+// which count their streams, allowing to track stream churn without
+// repeatedly snapshotting GetStreams.
+type ConnStreamCounter interface {
+	// NumStreams returns the number of streams currently open over the
+	// connection.
+	NumStreams() int
+
+	// NumStreamsOpened returns the number of streams ever opened over the
+	// connection, in either direction.
+	NumStreamsOpened() uint64
+}
+
+// NumStreams returns the number of streams currently open over c. If c
+// doesn't implement ConnStreamCounter, it falls back to counting the result
+// of GetStreams.
+func NumStreams(c Conn) int {
+	if sc, ok := c.(ConnStreamCounter); ok {
+		return sc.NumStreams()
+	}
+	return len(c.GetStreams())
+}
+
+// NumStreamsOpened returns the number of streams ever opened over c, and
+// whether c keeps track of it (see ConnStreamCounter).
+func NumStreamsOpened(c Conn) (uint64, bool) {
+	if sc, ok := c.(ConnStreamCounter); ok {
+		return sc.NumStreamsOpened(), true
+	}
+	return 0, false
+}
+
+// StreamCount implements ConnStreamCounter. Connection implementations can
+// embed it, and call StreamOpened and StreamClosed as streams come and go.
+//
+// The zero value is ready to use. As it is accessed atomically, it must be
+// 64-bit aligned on 32-bit platforms, e.g. by being the first field of the
+// struct it is embedded in.
+type StreamCount struct {
+	opened uint64
+	closed uint64
+}
+
+var _ ConnStreamCounter = (*StreamCount)(nil)
+
+// StreamOpened records a stream being opened.
+func (c *StreamCount) StreamOpened() {
+	atomic.AddUint64(&c.opened, 1)
+}
+
+// StreamClosed records a stream being closed or reset. It must be called
+// exactly once per stream.
+func (c *StreamCount) StreamClosed() {
+	atomic.AddUint64(&c.closed, 1)
+}
+
+// NumStreams implements ConnStreamCounter.
+func (c *StreamCount) NumStreams() int {
+	closed := atomic.LoadUint64(&c.closed)
+	return int(atomic.LoadUint64(&c.opened) - closed)
+}
+
+// NumStreamsOpened implements ConnStreamCounter.
+func (c *StreamCount) NumStreamsOpened() uint64 {
+	return atomic.LoadUint64(&c.opened)
+}
+
+// StreamsByDirection returns the streams currently open over c in the given
+// direction, as reported by their Stat.
+func StreamsByDirection(c Conn, dir Direction) []Stream {
+	var res []Stream
+	for _, s := range c.GetStreams() {
+		if s.Stat().Direction == dir {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// CountStreams returns the number of streams currently open over c in the
+// given direction, as reported by their Stat.
+func CountStreams(c Conn, dir Direction) int {
+	n := 0
+	for _, s := range c.GetStreams() {
+		if s.Stat().Direction == dir {
+			n++
+		}
+	}
+	return n
+}
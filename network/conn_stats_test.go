@@ -0,0 +1,68 @@
+package network
+
+import "testing"
+
+type listConn struct {
+	Conn
+	streams []Stream
+}
+
+func (c *listConn) GetStreams() []Stream { return c.streams }
+
+type countingConn struct {
+	StreamCount
+	listConn
+}
+
+func TestStreamCount(t *testing.T) {
+	c := &countingConn{}
+	for i := 0; i < 5; i++ {
+		c.StreamOpened()
+	}
+	c.StreamClosed()
+	c.StreamClosed()
+
+	if n := NumStreams(c); n != 3 {
+		t.Fatalf("expected 3 open streams, got %d", n)
+	}
+	if n, ok := NumStreamsOpened(c); !ok || n != 5 {
+		t.Fatalf("expected 5 streams opened, got %d", n)
+	}
+
+	// Connections that don't count their streams.
+	lc := &listConn{streams: make([]Stream, 2)}
+	if n := NumStreams(lc); n != 2 {
+		t.Fatalf("expected 2 open streams, got %d", n)
+	}
+	if _, ok := NumStreamsOpened(lc); ok {
+		t.Fatal("expected the number of streams opened to be unknown")
+	}
+}
+
+type dirStream struct {
+	Stream
+	dir Direction
+}
+
+func (s *dirStream) Stat() Stat { return Stat{Direction: s.dir} }
+
+func TestStreamsByDirection(t *testing.T) {
+	in1 := &dirStream{dir: DirInbound}
+	in2 := &dirStream{dir: DirInbound}
+	out := &dirStream{dir: DirOutbound}
+	c := &listConn{streams: []Stream{in1, out, in2}}
+
+	inbound := StreamsByDirection(c, DirInbound)
+	if len(inbound) != 2 || inbound[0] != in1 || inbound[1] != in2 {
+		t.Fatalf("expected the inbound streams, got %v", inbound)
+	}
+	if outbound := StreamsByDirection(c, DirOutbound); len(outbound) != 1 || outbound[0] != out {
+		t.Fatalf("expected the outbound stream, got %v", outbound)
+	}
+	if n := CountStreams(c, DirInbound); n != 2 {
+		t.Fatalf("expected 2 inbound streams, got %d", n)
+	}
+	if n := CountStreams(c, DirUnknown); n != 0 {
+		t.Fatalf("expected no streams of unknown direction, got %d", n)
+	}
+}
@@ -15,11 +15,13 @@ type dialPeerTimeoutCtxKey struct{}
 type forceDirectDialCtxKey struct{}
 type useTransientCtxKey struct{}
 type simConnectCtxKey struct{}
+type noRelayCtxKey struct{}
 
 var noDial = noDialCtxKey{}
 var forceDirectDial = forceDirectDialCtxKey{}
 var useTransient = useTransientCtxKey{}
 var simConnect = simConnectCtxKey{}
+var noRelay = noRelayCtxKey{}
 
 // EXPERIMENTAL
 // WithForceDirectDial constructs a new context with an option that instructs the network
@@ -57,6 +59,24 @@ func GetSimultaneousConnect(ctx context.Context) (simconnect bool, reason string
 	return false, ""
 }
 
+// EXPERIMENTAL
+// WithNoRelay constructs a new context with an option that instructs the network
+// to only dial direct addresses, and not to dial the peer through a relay.
+func WithNoRelay(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, noRelay, reason)
+}
+
+// EXPERIMENTAL
+// GetNoRelay returns true if the no relay option is set in the context.
+func GetNoRelay(ctx context.Context) (norelay bool, reason string) {
+	v := ctx.Value(noRelay)
+	if v != nil {
+		return true, v.(string)
+	}
+
+	return false, ""
+}
+
 // WithNoDial constructs a new context with an option that instructs the network
 // to not attempt a new dial when opening a stream.
 func WithNoDial(ctx context.Context, reason string) context.Context {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
 )
 
 func TestDefaultTimeout(t *testing.T) {
@@ -38,3 +40,47 @@ func TestSettingTimeout(t *testing.T) {
 		t.Fatal("peer timeout doesn't match set timeout")
 	}
 }
+
+type ctxDialer struct {
+	Dialer
+	ctx context.Context
+}
+
+func (d *ctxDialer) DialPeer(ctx context.Context, p peer.ID) (Conn, error) {
+	d.ctx = ctx
+	return nil, nil
+}
+
+func TestDialPeerWithOptions(t *testing.T) {
+	d := &ctxDialer{}
+	if _, err := DialPeerWithOptions(context.Background(), d, "peer"); err != nil {
+		t.Fatal(err)
+	}
+	if force, _ := GetForceDirectDial(d.ctx); force {
+		t.Fatal("expected no force direct dial option")
+	}
+	if norelay, _ := GetNoRelay(d.ctx); norelay {
+		t.Fatal("expected no no relay option")
+	}
+	if GetDialPeerTimeout(d.ctx) != DialPeerTimeout {
+		t.Fatal("expected the default timeout")
+	}
+
+	_, err := DialPeerWithOptions(context.Background(), d, "peer",
+		ForceDirectDial("hole punching"),
+		NoRelay("direct only"),
+		DialTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if force, reason := GetForceDirectDial(d.ctx); !force || reason != "hole punching" {
+		t.Fatal("expected the force direct dial option")
+	}
+	if norelay, reason := GetNoRelay(d.ctx); !norelay || reason != "direct only" {
+		t.Fatal("expected the no relay option")
+	}
+	if GetDialPeerTimeout(d.ctx) != time.Second {
+		t.Fatal("expected the dial timeout to be set")
+	}
+}
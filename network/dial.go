@@ -0,0 +1,82 @@
+package network
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// DialOptions holds the options of a DialPeerWithOptions call.
+type DialOptions struct {
+	// ForceDirect instructs the network to dial the peer directly, even if
+	// a relayed connection to it already exists. See WithForceDirectDial.
+	ForceDirect bool
+	// ForceDirectReason is the reason given for ForceDirect.
+	ForceDirectReason string
+
+	// NoRelay instructs the network not to dial the peer through a relay.
+	// See WithNoRelay.
+	NoRelay bool
+	// NoRelayReason is the reason given for NoRelay.
+	NoRelayReason string
+
+	// Timeout overrides DialPeerTimeout for this dial, if positive. See
+	// WithDialPeerTimeout.
+	Timeout time.Duration
+}
+
+// DialOption is an option for DialPeerWithOptions.
+type DialOption func(*DialOptions)
+
+// ForceDirectDial instructs the network to establish a direct connection to
+// the peer, even if a relayed connection to it already exists.
+func ForceDirectDial(reason string) DialOption {
+	return func(o *DialOptions) {
+		o.ForceDirect = true
+		o.ForceDirectReason = reason
+	}
+}
+
+// NoRelay instructs the network not to dial the peer through a relay.
+func NoRelay(reason string) DialOption {
+	return func(o *DialOptions) {
+		o.NoRelay = true
+		o.NoRelayReason = reason
+	}
+}
+
+// DialTimeout sets the timeout of the dial, overriding DialPeerTimeout.
+func DialTimeout(timeout time.Duration) DialOption {
+	return func(o *DialOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// DialPeerWithOptions dials p with d, applying the given options.
+//
+// The options are passed to the Dialer as context options (see
+// WithForceDirectDial, WithNoRelay and WithDialPeerTimeout), so that
+// existing implementations of DialPeer can honor them. Implementations which
+// don't know about an option simply ignore it.
+func DialPeerWithOptions(ctx context.Context, d Dialer, p peer.ID, opts ...DialOption) (Conn, error) {
+	var o DialOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return d.DialPeer(o.apply(ctx), p)
+}
+
+// apply returns a context carrying the options.
+func (o *DialOptions) apply(ctx context.Context) context.Context {
+	if o.ForceDirect {
+		ctx = WithForceDirectDial(ctx, o.ForceDirectReason)
+	}
+	if o.NoRelay {
+		ctx = WithNoRelay(ctx, o.NoRelayReason)
+	}
+	if o.Timeout > 0 {
+		ctx = WithDialPeerTimeout(ctx, o.Timeout)
+	}
+	return ctx
+}
@@ -0,0 +1,30 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// marshalEnum encodes the value v of an enum as a JSON string holding its
+// name, or "Unknown(<v>)" if v isn't a known value.
+func marshalEnum(names []string, v int) ([]byte, error) {
+	if v < 0 || v >= len(names) {
+		return json.Marshal(fmt.Sprintf("Unknown(%d)", v))
+	}
+	return json.Marshal(names[v])
+}
+
+// unmarshalEnum decodes a JSON string holding the name of a value of an enum,
+// and returns that value.
+func unmarshalEnum(names []string, typ string, b []byte) (int, error) {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", typ, err)
+	}
+	for v, n := range names {
+		if n == name {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s %q", typ, name)
+}
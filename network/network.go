@@ -35,12 +35,30 @@ const (
 	DirOutbound
 )
 
+var directionNames = []string{"Unknown", "Inbound", "Outbound"}
+
 func (d Direction) String() string {
-	str := [...]string{"Unknown", "Inbound", "Outbound"}
-	if d < 0 || int(d) >= len(str) {
+	if d < 0 || int(d) >= len(directionNames) {
 		return "(unrecognized)"
 	}
-	return str[d]
+	return directionNames[d]
+}
+
+// MarshalJSON encodes d as its name, or as "Unknown(<n>)" if d isn't a known
+// direction.
+func (d Direction) MarshalJSON() ([]byte, error) {
+	return marshalEnum(directionNames, int(d))
+}
+
+// UnmarshalJSON decodes a direction encoded by MarshalJSON. Unknown values
+// are rejected.
+func (d *Direction) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(directionNames, "direction", b)
+	if err != nil {
+		return err
+	}
+	*d = Direction(v)
+	return nil
 }
 
 // Connectedness signals the capacity for a connection with a given node.
@@ -62,12 +80,30 @@ const (
 	CannotConnect
 )
 
+var connectednessNames = []string{"NotConnected", "Connected", "CanConnect", "CannotConnect"}
+
 func (c Connectedness) String() string {
-	str := [...]string{"NotConnected", "Connected", "CanConnect", "CannotConnect"}
-	if c < 0 || int(c) >= len(str) {
+	if c < 0 || int(c) >= len(connectednessNames) {
 		return "(unrecognized)"
 	}
-	return str[c]
+	return connectednessNames[c]
+}
+
+// MarshalJSON encodes c as its name, or as "Unknown(<n>)" if c isn't a known
+// connectedness.
+func (c Connectedness) MarshalJSON() ([]byte, error) {
+	return marshalEnum(connectednessNames, int(c))
+}
+
+// UnmarshalJSON decodes a connectedness encoded by MarshalJSON. Unknown
+// values are rejected.
+func (c *Connectedness) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(connectednessNames, "connectedness", b)
+	if err != nil {
+		return err
+	}
+	*c = Connectedness(v)
+	return nil
 }
 
 // Reachability indicates how reachable a node is.
@@ -89,12 +125,30 @@ const (
 	ReachabilityPrivate
 )
 
+var reachabilityNames = []string{"Unknown", "Public", "Private"}
+
 func (r Reachability) String() string {
-	str := [...]string{"Unknown", "Public", "Private"}
-	if r < 0 || int(r) >= len(str) {
+	if r < 0 || int(r) >= len(reachabilityNames) {
 		return "(unrecognized)"
 	}
-	return str[r]
+	return reachabilityNames[r]
+}
+
+// MarshalJSON encodes r as its name, or as "Unknown(<n>)" if r isn't a known
+// reachability.
+func (r Reachability) MarshalJSON() ([]byte, error) {
+	return marshalEnum(reachabilityNames, int(r))
+}
+
+// UnmarshalJSON decodes a reachability encoded by MarshalJSON. Unknown values
+// are rejected.
+func (r *Reachability) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(reachabilityNames, "reachability", b)
+	if err != nil {
+		return err
+	}
+	*r = Reachability(v)
+	return nil
 }
 
 // Stat stores metadata pertaining to a given Stream/Conn.
@@ -107,6 +161,17 @@ type Stat struct {
 	Transient bool
 	// Extra stores additional metadata about this connection.
 	Extra map[interface{}]interface{}
+
+	// BytesRead is the number of bytes read from the stream so far. It is
+	// only populated for streams.
+	BytesRead uint64
+	// BytesWritten is the number of bytes written to the stream so far. It
+	// is only populated for streams.
+	BytesWritten uint64
+	// ProtocolNegotiatedAt is the time at which the stream's protocol was
+	// negotiated, or the zero time if it hasn't been yet. It is only
+	// populated for streams.
+	ProtocolNegotiatedAt time.Time
 }
 
 // StreamHandler is the type of function used to listen for
@@ -186,3 +251,27 @@ type Dialer interface {
 	Notify(Notifiee)
 	StopNotify(Notifiee)
 }
+
+// ConnectednessBatcher is an optional interface implemented by Dialers which
+// can report the connectedness of many peers at once more efficiently than
+// one at a time, e.g. by taking their locks only once.
+type ConnectednessBatcher interface {
+	// ConnectednessMany returns the connectedness of each of the given
+	// peers. The result is positionally aligned with peers.
+	ConnectednessMany(peers []peer.ID) []Connectedness
+}
+
+// ConnectednessMany returns the connectedness of each of the given peers,
+// using d's ConnectednessMany if it implements ConnectednessBatcher, and
+// calling Connectedness for each peer otherwise. The result is positionally
+// aligned with peers: the i-th element is the connectedness of peers[i].
+func ConnectednessMany(d Dialer, peers []peer.ID) []Connectedness {
+	if b, ok := d.(ConnectednessBatcher); ok {
+		return b.ConnectednessMany(peers)
+	}
+	res := make([]Connectedness, len(peers))
+	for i, p := range peers {
+		res[i] = d.Connectedness(p)
+	}
+	return res
+}
@@ -0,0 +1,104 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+type connectednessDialer struct {
+	Dialer
+	connected map[peer.ID]bool
+	calls     int
+}
+
+func (d *connectednessDialer) Connectedness(p peer.ID) Connectedness {
+	d.calls++
+	if d.connected[p] {
+		return Connected
+	}
+	return NotConnected
+}
+
+type batchingDialer struct {
+	*connectednessDialer
+}
+
+func (d batchingDialer) ConnectednessMany(peers []peer.ID) []Connectedness {
+	res := make([]Connectedness, len(peers))
+	for i, p := range peers {
+		if d.connected[p] {
+			res[i] = Connected
+		}
+	}
+	return res
+}
+
+func TestConnectednessMany(t *testing.T) {
+	peers := []peer.ID{"a", "b", "c"}
+	expected := []Connectedness{NotConnected, Connected, NotConnected}
+
+	check := func(res []Connectedness) {
+		t.Helper()
+		if len(res) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, res)
+		}
+		for i := range res {
+			if res[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, res)
+			}
+		}
+	}
+
+	d := &connectednessDialer{connected: map[peer.ID]bool{"b": true}}
+	check(ConnectednessMany(d, peers))
+	if d.calls != len(peers) {
+		t.Fatalf("expected Connectedness to be called for each peer, got %d calls", d.calls)
+	}
+
+	d.calls = 0
+	check(ConnectednessMany(batchingDialer{d}, peers))
+	if d.calls != 0 {
+		t.Fatal("expected the batched implementation to be used")
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	type status struct {
+		Connectedness Connectedness
+		Direction     Direction
+		Reachability  Reachability
+	}
+	in := status{CanConnect, DirOutbound, ReachabilityPrivate}
+	out, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Connectedness":"CanConnect","Direction":"Outbound","Reachability":"Private"}`
+	if string(out) != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+	var decoded status
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != in {
+		t.Fatalf("expected %+v, got %+v", in, decoded)
+	}
+
+	out, err = json.Marshal(status{Connectedness(7), Direction(-1), Reachability(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"Connectedness":"Unknown(7)","Direction":"Unknown(-1)","Reachability":"Unknown(3)"}`
+	if string(out) != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+	if err := json.Unmarshal(out, &decoded); err == nil {
+		t.Fatal("expected unknown values to fail to unmarshal")
+	}
+	if err := json.Unmarshal([]byte(`{"Connectedness":1}`), &decoded); err == nil {
+		t.Fatal("expected numeric values to fail to unmarshal")
+	}
+}
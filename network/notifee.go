@@ -1,6 +1,8 @@
 package network
 
 import (
+	"github.com/libp2p/go-libp2p-core/peer"
+
 	ma "github.com/multiformats/go-multiaddr"
 )
 
@@ -19,6 +21,26 @@ type Notifiee interface {
 	// PeerDisconnected(Network, peer.ID) // called when a peer disconnected
 }
 
+// ConnectednessNotifiee is an optional interface for Notifiees that wish to be
+// notified of peer-level connectedness changes, instead of inferring them from
+// per-connection notifications.
+//
+// Networks call ConnectednessChanged only when the Connectedness of a peer
+// actually changes: when the first connection to a peer is opened, and when
+// the last one is closed. Opening or closing additional connections to an
+// already connected peer doesn't trigger it.
+//
+// For a given peer, ConnectednessChanged(Connected) is called after Connected
+// has been called for the first connection, and
+// ConnectednessChanged(NotConnected) after Disconnected has been called for the
+// last one.
+type ConnectednessNotifiee interface {
+	Notifiee
+
+	// ConnectednessChanged is called when the connectedness of a peer changes.
+	ConnectednessChanged(Network, peer.ID, Connectedness)
+}
+
 // NotifyBundle implements Notifiee by calling any of the functions set on it,
 // and nop'ing if they are unset. This is the easy way to register for
 // notifications.
@@ -31,9 +53,11 @@ type NotifyBundle struct {
 
 	OpenedStreamF func(Network, Stream)
 	ClosedStreamF func(Network, Stream)
+
+	ConnectednessChangedF func(Network, peer.ID, Connectedness)
 }
 
-var _ Notifiee = (*NotifyBundle)(nil)
+var _ ConnectednessNotifiee = (*NotifyBundle)(nil)
 
 // Listen calls ListenF if it is not null.
 func (nb *NotifyBundle) Listen(n Network, a ma.Multiaddr) {
@@ -77,12 +101,19 @@ func (nb *NotifyBundle) ClosedStream(n Network, s Stream) {
 	}
 }
 
+// ConnectednessChanged calls ConnectednessChangedF if it is not null.
+func (nb *NotifyBundle) ConnectednessChanged(n Network, p peer.ID, c Connectedness) {
+	if nb.ConnectednessChangedF != nil {
+		nb.ConnectednessChangedF(n, p, c)
+	}
+}
+
 // Global noop notifiee. Do not change.
 var GlobalNoopNotifiee = &NoopNotifiee{}
 
 type NoopNotifiee struct{}
 
-var _ Notifiee = (*NoopNotifiee)(nil)
+var _ ConnectednessNotifiee = (*NoopNotifiee)(nil)
 
 func (nn *NoopNotifiee) Connected(n Network, c Conn)              {}
 func (nn *NoopNotifiee) Disconnected(n Network, c Conn)           {}
@@ -90,3 +121,5 @@ func (nn *NoopNotifiee) Listen(n Network, addr ma.Multiaddr)      {}
 func (nn *NoopNotifiee) ListenClose(n Network, addr ma.Multiaddr) {}
 func (nn *NoopNotifiee) OpenedStream(Network, Stream)             {}
 func (nn *NoopNotifiee) ClosedStream(Network, Stream)             {}
+
+func (nn *NoopNotifiee) ConnectednessChanged(Network, peer.ID, Connectedness) {}
@@ -3,6 +3,8 @@ package network
 import (
 	"testing"
 
+	"github.com/libp2p/go-libp2p-core/peer"
+
 	ma "github.com/multiformats/go-multiaddr"
 )
 
@@ -121,3 +123,26 @@ func TestClosedStream(T *testing.T) {
 		T.Fatal("ClosedStream should have been called")
 	}
 }
+
+func TestConnectednessChanged(T *testing.T) {
+	var notifee NotifyBundle
+	notifee.ConnectednessChanged(nil, "", Connected)
+
+	var got Connectedness
+	called := false
+	notifee.ConnectednessChangedF = func(_ Network, _ peer.ID, c Connectedness) {
+		called = true
+		got = c
+	}
+	if called {
+		T.Fatal("called should be false")
+	}
+
+	notifee.ConnectednessChanged(nil, "", NotConnected)
+	if !called {
+		T.Fatal("ConnectednessChanged should have been called")
+	}
+	if got != NotConnected {
+		T.Fatalf("expected %s, got %s", NotConnected, got)
+	}
+}
@@ -0,0 +1,131 @@
+package network
+
+// ResourceScope is the interface of a resource accounting scope, to which
+// connections and streams charge the resources they use. Scopes are
+// provided by the resource manager; when there is none, NullScope is used.
+type ResourceScope interface {
+	// ReserveMemory reserves size bytes of memory in the scope, failing if
+	// the reservation would exceed the scope's limit. prio is the priority
+	// of the reservation (see the ReservationPriority constants): the lower
+	// it is, the earlier reservations fail as the scope fills up.
+	ReserveMemory(size int, prio uint8) error
+
+	// ReleaseMemory releases size bytes of memory previously reserved with
+	// ReserveMemory.
+	ReleaseMemory(size int)
+
+	// Stat returns the resources currently accounted to the scope.
+	Stat() ScopeStat
+
+	// BeginSpan creates a span of the scope, see ResourceScopeSpan.
+	BeginSpan() (ResourceScopeSpan, error)
+}
+
+// ResourceScopeSpan is a short-lived child of a ResourceScope. The resources
+// reserved in a span are accounted to its parent scope as well, and all of
+// them are released at once when the span is done. This allows accounting
+// e.g. each request processed over a long-lived stream separately, so that
+// a leaked reservation doesn't outlive the request.
+//
+// Spans can be nested, as a span is a ResourceScope itself: reservations in
+// a nested span are accounted to all its ancestors, up to the scope the
+// outermost span was created from. Nested spans must be done before their
+// parent.
+type ResourceScopeSpan interface {
+	ResourceScope
+
+	// Done ends the span, releasing all its reservations.
+	Done()
+}
+
+// ConnScope is the scope a connection is accounted to.
+type ConnScope interface {
+	ResourceScope
+}
+
+// StreamScope is the scope a stream is accounted to. Use BeginSpan (or
+// WithSpan) to account the resources of individual requests processed over
+// the stream.
+type StreamScope interface {
+	ResourceScope
+}
+
+// ScopeStat holds the resources accounted to a scope.
+type ScopeStat struct {
+	NumStreamsInbound  int
+	NumStreamsOutbound int
+	NumConnsInbound    int
+	NumConnsOutbound   int
+	NumFD              int
+
+	Memory int64
+}
+
+// Priorities of memory reservations. The value of a priority is the
+// percentage of the scope's memory limit (scaled to 255) up to which
+// reservations made with it succeed.
+const (
+	// ReservationPriorityLow succeeds up to 40% of the limit.
+	ReservationPriorityLow uint8 = 101
+	// ReservationPriorityMedium succeeds up to 60% of the limit.
+	ReservationPriorityMedium uint8 = 152
+	// ReservationPriorityHigh succeeds up to 80% of the limit.
+	ReservationPriorityHigh uint8 = 203
+	// ReservationPriorityAlways succeeds as long as the limit isn't
+	// exceeded.
+	ReservationPriorityAlways uint8 = 255
+)
+
+// NullScope is a scope that doesn't account for or limit anything. It is
+// used when there is no resource manager.
+var NullScope = &nullScope{}
+
+type nullScope struct{}
+
+var _ ResourceScopeSpan = (*nullScope)(nil)
+var _ ConnScope = (*nullScope)(nil)
+var _ StreamScope = (*nullScope)(nil)
+
+func (n *nullScope) ReserveMemory(size int, prio uint8) error { return nil }
+func (n *nullScope) ReleaseMemory(size int)                   {}
+func (n *nullScope) Stat() ScopeStat                          { return ScopeStat{} }
+func (n *nullScope) BeginSpan() (ResourceScopeSpan, error)    { return NullScope, nil }
+func (n *nullScope) Done()                                    {}
+
+// ScopedConn is an optional interface implemented by connections accounted
+// to a resource scope.
+type ScopedConn interface {
+	// Scope returns the scope the connection is accounted to.
+	Scope() ConnScope
+}
+
+// ConnScopeOf returns the scope c is accounted to, or NullScope if c doesn't
+// implement ScopedConn.
+func ConnScopeOf(c Conn) ConnScope {
+	if sc, ok := c.(ScopedConn); ok {
+		return sc.Scope()
+	}
+	return NullScope
+}
+
+// WithMemory reserves size bytes of memory in scope with the given priority,
+// runs fn, and releases the reservation when fn returns, even if it panics.
+// fn isn't run if the reservation fails.
+func WithMemory(scope ResourceScope, size int, prio uint8, fn func() error) error {
+	if err := scope.ReserveMemory(size, prio); err != nil {
+		return err
+	}
+	defer scope.ReleaseMemory(size)
+	return fn()
+}
+
+// WithSpan begins a span of scope, runs fn with it, and ends the span when fn
+// returns, even if it panics, releasing all the reservations made in it.
+func WithSpan(scope ResourceScope, fn func(ResourceScopeSpan) error) error {
+	span, err := scope.BeginSpan()
+	if err != nil {
+		return err
+	}
+	defer span.Done()
+	return fn(span)
+}
@@ -0,0 +1,168 @@
+package network
+
+import (
+	"errors"
+	"testing"
+)
+
+type unscopedConn struct{ Conn }
+
+type scopedConn struct {
+	Conn
+	scope ConnScope
+}
+
+func (c scopedConn) Scope() ConnScope { return c.scope }
+
+var errLimitExceeded = errors.New("limit exceeded")
+
+type testScope struct {
+	nullScope
+	memory int64
+	limit  int64
+}
+
+func (s *testScope) ReserveMemory(size int, prio uint8) error {
+	if s.memory+int64(size) > s.limit {
+		return errLimitExceeded
+	}
+	s.memory += int64(size)
+	return nil
+}
+
+func (s *testScope) ReleaseMemory(size int) {
+	s.memory -= int64(size)
+}
+
+func TestConnScopeOf(t *testing.T) {
+	if s := ConnScopeOf(unscopedConn{}); s != NullScope {
+		t.Fatalf("expected the null scope, got %v", s)
+	}
+
+	scope := &testScope{}
+	if s := ConnScopeOf(scopedConn{scope: scope}); s != scope {
+		t.Fatalf("expected the connection's scope, got %v", s)
+	}
+}
+
+func TestNullScope(t *testing.T) {
+	if err := NullScope.ReserveMemory(1<<30, ReservationPriorityAlways); err != nil {
+		t.Fatal(err)
+	}
+	NullScope.ReleaseMemory(1 << 30)
+	if st := NullScope.Stat(); st != (ScopeStat{}) {
+		t.Fatalf("expected empty stats, got %+v", st)
+	}
+	span, err := NullScope.BeginSpan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	span.Done()
+}
+
+func TestWithMemory(t *testing.T) {
+	scope := &testScope{limit: 1024}
+
+	err := WithMemory(scope, 512, ReservationPriorityHigh, func() error {
+		if scope.memory != 512 {
+			t.Fatalf("expected 512 bytes to be reserved, got %d", scope.memory)
+		}
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Fatalf("expected the callback's error, got %v", err)
+	}
+	if scope.memory != 0 {
+		t.Fatalf("expected the reservation to be released, got %d bytes", scope.memory)
+	}
+
+	called := false
+	err = WithMemory(scope, 2048, ReservationPriorityHigh, func() error {
+		called = true
+		return nil
+	})
+	if err != errLimitExceeded || called {
+		t.Fatalf("expected the reservation to fail without calling the callback, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the panic to propagate")
+			}
+		}()
+		WithMemory(scope, 512, ReservationPriorityHigh, func() error {
+			panic("boom")
+		})
+	}()
+	if scope.memory != 0 {
+		t.Fatalf("expected the reservation to be released after a panic, got %d bytes", scope.memory)
+	}
+}
+
+// testSpan is a span of a testScope, tracking its own reservations.
+type testSpan struct {
+	*testScope
+	reserved int
+	done     bool
+}
+
+func (s *testSpan) ReserveMemory(size int, prio uint8) error {
+	if err := s.testScope.ReserveMemory(size, prio); err != nil {
+		return err
+	}
+	s.reserved += size
+	return nil
+}
+
+func (s *testSpan) Done() {
+	s.testScope.ReleaseMemory(s.reserved)
+	s.reserved = 0
+	s.done = true
+}
+
+type spanningScope struct {
+	*testScope
+	spans []*testSpan
+}
+
+func (s *spanningScope) BeginSpan() (ResourceScopeSpan, error) {
+	span := &testSpan{testScope: s.testScope}
+	s.spans = append(s.spans, span)
+	return span, nil
+}
+
+func TestWithSpan(t *testing.T) {
+	scope := &spanningScope{testScope: &testScope{limit: 1024}}
+
+	err := WithSpan(scope, func(span ResourceScopeSpan) error {
+		for i := 0; i < 3; i++ {
+			if err := span.ReserveMemory(100, ReservationPriorityAlways); err != nil {
+				return err
+			}
+		}
+		if scope.memory != 300 {
+			t.Fatalf("expected the span's reservations to be accounted to the scope, got %d bytes", scope.memory)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scope.spans) != 1 || !scope.spans[0].done {
+		t.Fatal("expected the span to be done")
+	}
+	if scope.memory != 0 {
+		t.Fatalf("expected the span's reservations to be released, got %d bytes", scope.memory)
+	}
+
+	err = WithSpan(scope, func(span ResourceScopeSpan) error {
+		return span.ReserveMemory(2048, ReservationPriorityAlways)
+	})
+	if err != errLimitExceeded {
+		t.Fatalf("expected the callback's error, got %v", err)
+	}
+	if !scope.spans[1].done {
+		t.Fatal("expected the span to be done")
+	}
+}
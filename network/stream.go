@@ -1,6 +1,8 @@
 package network
 
 import (
+	"time"
+
 	"github.com/libp2p/go-libp2p-core/mux"
 	"github.com/libp2p/go-libp2p-core/protocol"
 )
@@ -26,3 +28,33 @@ type Stream interface {
 	// Conn returns the connection this stream is part of.
 	Conn() Conn
 }
+
+// SetStreamReadTimeout sets the read deadline of the stream to d from now. A
+// zero duration clears the deadline.
+func SetStreamReadTimeout(s Stream, d time.Duration) error {
+	return s.SetReadDeadline(deadlineFromTimeout(d))
+}
+
+// SetStreamWriteTimeout sets the write deadline of the stream to d from now.
+// A zero duration clears the deadline.
+func SetStreamWriteTimeout(s Stream, d time.Duration) error {
+	return s.SetWriteDeadline(deadlineFromTimeout(d))
+}
+
+// WithTimeout sets both the read and write deadlines of the stream to d from
+// now, calls fn, and clears the deadlines afterwards, even if fn panics. A
+// zero duration means no deadline.
+func WithTimeout(s Stream, d time.Duration, fn func(Stream) error) error {
+	if err := s.SetDeadline(deadlineFromTimeout(d)); err != nil {
+		return err
+	}
+	defer s.SetDeadline(time.Time{})
+	return fn(s)
+}
+
+func deadlineFromTimeout(d time.Duration) time.Time {
+	if d == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(d)
+}
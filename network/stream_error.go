@@ -0,0 +1,91 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/mux"
+)
+
+// StreamErrorCode is a numeric reason for resetting a stream, propagated to
+// the remote peer by transports that support it (e.g. QUIC).
+type StreamErrorCode uint32
+
+// Well-known stream error codes. Protocols may define their own codes above
+// StreamErrorCodeProtocolStart.
+const (
+	// StreamNoError signals a reset without a specific reason.
+	StreamNoError StreamErrorCode = 0
+	// StreamProtocolViolation signals that the remote violated the protocol
+	// spoken on the stream.
+	StreamProtocolViolation StreamErrorCode = 1
+	// StreamResourceLimitExceeded signals that the stream was reset because
+	// a resource limit was hit.
+	StreamResourceLimitExceeded StreamErrorCode = 2
+	// StreamProtocolNegotiationFailed signals that no protocol could be
+	// agreed upon for the stream.
+	StreamProtocolNegotiationFailed StreamErrorCode = 3
+	// StreamShutdown signals that the stream was reset because the node is
+	// shutting down.
+	StreamShutdown StreamErrorCode = 4
+
+	// StreamErrorCodeProtocolStart is the first error code available for
+	// protocol-specific use.
+	StreamErrorCodeProtocolStart StreamErrorCode = 0x1000
+)
+
+func (c StreamErrorCode) String() string {
+	switch c {
+	case StreamNoError:
+		return "no error"
+	case StreamProtocolViolation:
+		return "protocol violation"
+	case StreamResourceLimitExceeded:
+		return "resource limit exceeded"
+	case StreamProtocolNegotiationFailed:
+		return "protocol negotiation failed"
+	case StreamShutdown:
+		return "shutdown"
+	default:
+		return fmt.Sprintf("error code %d", uint32(c))
+	}
+}
+
+// StreamErrorResetter is implemented by streams that can propagate an error
+// code to the remote peer when being reset.
+type StreamErrorResetter interface {
+	// ResetWithError resets the stream, like Reset, and signals the given
+	// error code to the remote peer.
+	ResetWithError(StreamErrorCode) error
+}
+
+// ResetWithError resets the stream with the given error code, if the stream
+// supports it (see StreamErrorResetter). Otherwise, it falls back to a plain
+// Reset and the error code is lost.
+func ResetWithError(s mux.MuxedStream, code StreamErrorCode) error {
+	if r, ok := s.(StreamErrorResetter); ok {
+		return r.ResetWithError(code)
+	}
+	return s.Reset()
+}
+
+// StreamError is returned by operations on a stream that was reset with an
+// error code. It matches mux.ErrReset when using errors.Is, so existing
+// reset handling keeps working.
+type StreamError struct {
+	ErrorCode StreamErrorCode
+	// Remote is true if the stream was reset by the remote peer.
+	Remote bool
+}
+
+func (e *StreamError) Error() string {
+	side := "local"
+	if e.Remote {
+		side = "remote"
+	}
+	return fmt.Sprintf("stream reset (%s): %s", side, e.ErrorCode)
+}
+
+// Is makes StreamErrors match mux.ErrReset.
+func (e *StreamError) Is(target error) bool {
+	return target == mux.ErrReset
+}
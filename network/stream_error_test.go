@@ -0,0 +1,68 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/mux"
+)
+
+type resetStream struct {
+	Stream
+
+	reset bool
+}
+
+func (s *resetStream) Reset() error {
+	s.reset = true
+	return nil
+}
+
+type errorResetStream struct {
+	resetStream
+
+	code *StreamErrorCode
+}
+
+func (s *errorResetStream) ResetWithError(code StreamErrorCode) error {
+	s.code = &code
+	return nil
+}
+
+func TestResetWithError(t *testing.T) {
+	plain := new(resetStream)
+	if err := ResetWithError(plain, StreamProtocolViolation); err != nil {
+		t.Fatal(err)
+	}
+	if !plain.reset {
+		t.Fatal("expected a fallback to Reset")
+	}
+
+	withCode := new(errorResetStream)
+	if err := ResetWithError(withCode, StreamResourceLimitExceeded); err != nil {
+		t.Fatal(err)
+	}
+	if withCode.reset {
+		t.Fatal("didn't expect a fallback to Reset")
+	}
+	if withCode.code == nil || *withCode.code != StreamResourceLimitExceeded {
+		t.Fatal("expected the error code to be passed on")
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	var err error = &StreamError{ErrorCode: StreamProtocolViolation, Remote: true}
+	if !errors.Is(err, mux.ErrReset) {
+		t.Fatal("expected StreamError to match mux.ErrReset")
+	}
+	var serr *StreamError
+	if !errors.As(err, &serr) || serr.ErrorCode != StreamProtocolViolation {
+		t.Fatal("expected to extract the error code")
+	}
+	if err.Error() != "stream reset (remote): protocol violation" {
+		t.Fatalf("unexpected error message %q", err)
+	}
+	if s := StreamErrorCode(0x1234).String(); s != "error code 4660" {
+		t.Fatalf("unexpected string %q", s)
+	}
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// StreamCounters tracks the per-stream statistics reported in Stat. Stream
+// implementations can embed it, update it as I/O happens, and use Fill to
+// populate the Stat returned by Stream.Stat.
+//
+// The counters are monotonic and safe to update and read concurrently. The
+// zero value is ready to use. As it is accessed atomically, it must be
+// 64-bit aligned on 32-bit platforms, e.g. by being the first field of the
+// struct it is embedded in.
+type StreamCounters struct {
+	bytesRead            uint64
+	bytesWritten         uint64
+	protocolNegotiatedAt int64 // unix nanoseconds, 0 if unset
+}
+
+// AddRead records n bytes read from the stream. Non-positive values are
+// ignored.
+func (c *StreamCounters) AddRead(n int) {
+	if n > 0 {
+		atomic.AddUint64(&c.bytesRead, uint64(n))
+	}
+}
+
+// AddWritten records n bytes written to the stream. Non-positive values are
+// ignored.
+func (c *StreamCounters) AddWritten(n int) {
+	if n > 0 {
+		atomic.AddUint64(&c.bytesWritten, uint64(n))
+	}
+}
+
+// SetProtocolNegotiated records the time at which the stream's protocol was
+// negotiated. Only the first call has any effect.
+func (c *StreamCounters) SetProtocolNegotiated(t time.Time) {
+	atomic.CompareAndSwapInt64(&c.protocolNegotiatedAt, 0, t.UnixNano())
+}
+
+// Fill copies the current values of the counters into st.
+func (c *StreamCounters) Fill(st *Stat) {
+	st.BytesRead = atomic.LoadUint64(&c.bytesRead)
+	st.BytesWritten = atomic.LoadUint64(&c.bytesWritten)
+	if ns := atomic.LoadInt64(&c.protocolNegotiatedAt); ns != 0 {
+		st.ProtocolNegotiatedAt = time.Unix(0, ns)
+	} else {
+		st.ProtocolNegotiatedAt = time.Time{}
+	}
+}
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamCounters(t *testing.T) {
+	var c StreamCounters
+
+	var st Stat
+	c.Fill(&st)
+	if st.BytesRead != 0 || st.BytesWritten != 0 || !st.ProtocolNegotiatedAt.IsZero() {
+		t.Fatalf("expected zero stats, got %+v", st)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.AddRead(3)
+				c.AddWritten(5)
+				c.AddRead(-1)
+				var st Stat
+				c.Fill(&st)
+			}
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	c.SetProtocolNegotiated(now)
+	c.SetProtocolNegotiated(now.Add(time.Hour))
+
+	c.Fill(&st)
+	if st.BytesRead != 3000 {
+		t.Fatalf("expected 3000 bytes read, got %d", st.BytesRead)
+	}
+	if st.BytesWritten != 5000 {
+		t.Fatalf("expected 5000 bytes written, got %d", st.BytesWritten)
+	}
+	if !st.ProtocolNegotiatedAt.Equal(now) {
+		t.Fatalf("expected protocol negotiation time %s, got %s", now, st.ProtocolNegotiatedAt)
+	}
+}
@@ -0,0 +1,93 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type deadlineStream struct {
+	Stream
+
+	readDeadline, writeDeadline time.Time
+}
+
+func (s *deadlineStream) SetDeadline(t time.Time) error {
+	s.readDeadline, s.writeDeadline = t, t
+	return nil
+}
+
+func (s *deadlineStream) SetReadDeadline(t time.Time) error {
+	s.readDeadline = t
+	return nil
+}
+
+func (s *deadlineStream) SetWriteDeadline(t time.Time) error {
+	s.writeDeadline = t
+	return nil
+}
+
+func TestStreamTimeouts(t *testing.T) {
+	s := new(deadlineStream)
+
+	if err := SetStreamReadTimeout(s, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if s.readDeadline.IsZero() || !s.writeDeadline.IsZero() {
+		t.Fatal("expected only the read deadline to be set")
+	}
+	if err := SetStreamWriteTimeout(s, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if s.writeDeadline.IsZero() {
+		t.Fatal("expected the write deadline to be set")
+	}
+	if err := SetStreamReadTimeout(s, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !s.readDeadline.IsZero() {
+		t.Fatal("expected a zero timeout to clear the read deadline")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	s := new(deadlineStream)
+	expectedErr := errors.New("oops")
+
+	err := WithTimeout(s, time.Minute, func(s Stream) error {
+		ds := s.(*deadlineStream)
+		if ds.readDeadline.IsZero() || ds.writeDeadline.IsZero() {
+			t.Error("expected the deadlines to be set")
+		}
+		return expectedErr
+	})
+	if err != expectedErr {
+		t.Fatalf("expected the callback's error, got %v", err)
+	}
+	if !s.readDeadline.IsZero() || !s.writeDeadline.IsZero() {
+		t.Fatal("expected the deadlines to be cleared")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the panic to propagate")
+			}
+		}()
+		WithTimeout(s, time.Minute, func(Stream) error { panic("boom") })
+	}()
+	if !s.readDeadline.IsZero() || !s.writeDeadline.IsZero() {
+		t.Fatal("expected the deadlines to be cleared after a panic")
+	}
+
+	err = WithTimeout(s, 0, func(s Stream) error {
+		ds := s.(*deadlineStream)
+		if !ds.readDeadline.IsZero() || !ds.writeDeadline.IsZero() {
+			t.Error("expected no deadline with a zero timeout")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
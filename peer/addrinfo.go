@@ -2,6 +2,8 @@ package peer
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	ma "github.com/multiformats/go-multiaddr"
 )
@@ -44,6 +46,112 @@ func AddrInfosFromP2pAddrs(maddrs ...ma.Multiaddr) ([]AddrInfo, error) {
 	return ais, nil
 }
 
+// AddrError records a multiaddr that couldn't be converted to an AddrInfo.
+type AddrError struct {
+	Addr ma.Multiaddr
+	Err  error
+}
+
+func (e *AddrError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Addr, e.Err)
+}
+
+func (e *AddrError) Unwrap() error {
+	return e.Err
+}
+
+// AddrErrors is a list of errors, one for each multiaddr that couldn't be
+// converted to an AddrInfo.
+type AddrErrors []*AddrError
+
+func (es AddrErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d invalid p2p multiaddrs: %s", len(es), strings.Join(msgs, "; "))
+}
+
+// AddrInfosFromP2pAddrsSorted is like AddrInfosFromP2pAddrs, but returns the
+// AddrInfos sorted by peer ID, for deterministic output.
+//
+// Invalid multiaddrs (e.g. without a /p2p component) don't fail the whole
+// batch: they're skipped and reported individually in the returned error, of
+// type AddrErrors. The AddrInfos of the valid multiaddrs are always returned.
+func AddrInfosFromP2pAddrsSorted(maddrs ...ma.Multiaddr) ([]AddrInfo, error) {
+	var (
+		errs  AddrErrors
+		valid = make([]ma.Multiaddr, 0, len(maddrs))
+	)
+	for _, maddr := range maddrs {
+		if _, id := SplitAddr(maddr); id == "" {
+			errs = append(errs, &AddrError{Addr: maddr, Err: ErrInvalidAddr})
+			continue
+		}
+		valid = append(valid, maddr)
+	}
+
+	// Can't fail, we've filtered out the invalid addresses.
+	ais, _ := AddrInfosFromP2pAddrs(valid...)
+	sort.Slice(ais, func(i, j int) bool { return ais[i].ID < ais[j].ID })
+
+	if len(errs) > 0 {
+		return ais, errs
+	}
+	return ais, nil
+}
+
+// Merge returns a new AddrInfo holding the addresses of both pi and other,
+// without duplicates. The addresses of pi come first, in their original order,
+// followed by the new addresses of other.
+//
+// Merging AddrInfos of different peers is an error.
+func (pi AddrInfo) Merge(other AddrInfo) (AddrInfo, error) {
+	if pi.ID != other.ID {
+		return AddrInfo{}, fmt.Errorf("can't merge the addresses of different peers: %s and %s", pi.ID, other.ID)
+	}
+	merged := MergeAddrInfos(pi, other)
+	if len(merged) == 0 {
+		return AddrInfo{ID: pi.ID}, nil
+	}
+	return merged[0], nil
+}
+
+// MergeAddrInfos groups the given AddrInfos by peer ID, merging the addresses
+// of each peer without duplicates.
+//
+// The result is deterministic: peers are returned in the order they first
+// appear in, and addresses in the order they were first seen for that peer.
+func MergeAddrInfos(infos ...AddrInfo) []AddrInfo {
+	var (
+		out   []AddrInfo
+		index = make(map[ID]int, len(infos))
+		// Multiaddrs are equal iff their byte representations are equal.
+		seen = make(map[ID]map[string]struct{}, len(infos))
+	)
+	for _, info := range infos {
+		i, ok := index[info.ID]
+		if !ok {
+			i = len(out)
+			index[info.ID] = i
+			seen[info.ID] = make(map[string]struct{}, len(info.Addrs))
+			out = append(out, AddrInfo{ID: info.ID})
+		}
+		for _, addr := range info.Addrs {
+			if addr == nil {
+				continue
+			}
+			key := string(addr.Bytes())
+			if _, dup := seen[info.ID][key]; dup {
+				continue
+			}
+			seen[info.ID][key] = struct{}{}
+			out[i].Addrs = append(out[i].Addrs, addr)
+		}
+	}
+	return out
+}
+
 // SplitAddr splits a p2p Multiaddr into a transport multiaddr and a peer ID.
 //
 // * Returns a nil transport if the address only contains a /p2p part.
@@ -2,6 +2,7 @@ package peer
 
 import (
 	"encoding/json"
+	"fmt"
 
 	ma "github.com/multiformats/go-multiaddr"
 )
@@ -12,6 +13,10 @@ type addrInfoJson struct {
 	Addrs []string
 }
 
+// MarshalJSON encodes pi as a JSON object holding its base58-encoded peer ID
+// and the string forms of its addresses, e.g.
+//
+//   {"ID":"Qm...","Addrs":["/ip4/1.2.3.4/tcp/4001"]}
 func (pi AddrInfo) MarshalJSON() ([]byte, error) {
 	addrs := make([]string, len(pi.Addrs))
 	for i, addr := range pi.Addrs {
@@ -23,6 +28,8 @@ func (pi AddrInfo) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// UnmarshalJSON decodes an AddrInfo encoded by MarshalJSON. It fails if any
+// of the addresses is invalid, with an error naming the offending address.
 func (pi *AddrInfo) UnmarshalJSON(b []byte) error {
 	var data addrInfoJson
 	if err := json.Unmarshal(b, &data); err != nil {
@@ -32,7 +39,7 @@ func (pi *AddrInfo) UnmarshalJSON(b []byte) error {
 	for i, addr := range data.Addrs {
 		maddr, err := ma.NewMultiaddr(addr)
 		if err != nil {
-			return err
+			return fmt.Errorf("invalid multiaddr %q: %w", addr, err)
 		}
 		addrs[i] = maddr
 	}
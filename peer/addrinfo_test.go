@@ -1,6 +1,9 @@
 package peer_test
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	ma "github.com/multiformats/go-multiaddr"
@@ -149,4 +152,106 @@ func TestAddrInfoJSON(t *testing.T) {
 	if len(addrInfo.Addrs) != 1 || !addrInfo.Addrs[0].Equal(maddrFull) {
 		t.Fatalf("expected addrs to match %v, got %v", maddrFull, addrInfo.Addrs)
 	}
+
+	expected := fmt.Sprintf(`{"ID":"%s","Addrs":["%s"]}`, testID.Pretty(), maddrFull)
+	if string(out) != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+
+	err = addrInfo.UnmarshalJSON([]byte(`{"ID":"` + testID.Pretty() + `","Addrs":["/ip4/1.2.3.4/tcp/1","/ip4/999/tcp"]}`))
+	if err == nil || !strings.Contains(err.Error(), `"/ip4/999/tcp"`) {
+		t.Fatalf("expected an error naming the invalid address, got %v", err)
+	}
+}
+
+func TestAddrInfoMerge(t *testing.T) {
+	a1 := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	a2 := ma.StringCast("/ip4/1.2.3.4/tcp/2")
+	a3 := ma.StringCast("/ip4/1.2.3.4/tcp/3")
+
+	merged, err := AddrInfo{ID: testID, Addrs: []ma.Multiaddr{a2, a1}}.Merge(
+		AddrInfo{ID: testID, Addrs: []ma.Multiaddr{ma.StringCast("/ip4/1.2.3.4/tcp/1"), a3}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID != testID {
+		t.Fatal("wrong peer ID")
+	}
+	expected := []ma.Multiaddr{a2, a1, a3}
+	if len(merged.Addrs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, merged.Addrs)
+	}
+	for i := range expected {
+		if !merged.Addrs[i].Equal(expected[i]) {
+			t.Fatalf("expected %v, got %v", expected, merged.Addrs)
+		}
+	}
+
+	if _, err := (AddrInfo{ID: testID}).Merge(AddrInfo{ID: "other"}); err == nil {
+		t.Fatal("expected merging different peers to fail")
+	}
+}
+
+func TestMergeAddrInfos(t *testing.T) {
+	other := ID("other")
+	a1 := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	a2 := ma.StringCast("/ip4/1.2.3.4/tcp/2")
+
+	merged := MergeAddrInfos(
+		AddrInfo{ID: other, Addrs: []ma.Multiaddr{a1}},
+		AddrInfo{ID: testID, Addrs: []ma.Multiaddr{a2}},
+		AddrInfo{ID: other, Addrs: []ma.Multiaddr{a2, a1}},
+		AddrInfo{ID: testID, Addrs: []ma.Multiaddr{a2}},
+	)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 AddrInfos, got %d", len(merged))
+	}
+	if merged[0].ID != other || len(merged[0].Addrs) != 2 || !merged[0].Addrs[0].Equal(a1) || !merged[0].Addrs[1].Equal(a2) {
+		t.Fatalf("unexpected first AddrInfo: %s", merged[0])
+	}
+	if merged[1].ID != testID || len(merged[1].Addrs) != 1 || !merged[1].Addrs[0].Equal(a2) {
+		t.Fatalf("unexpected second AddrInfo: %s", merged[1])
+	}
+}
+
+func TestAddrInfosFromP2pAddrsSorted(t *testing.T) {
+	noP2p := ma.StringCast("/ip4/1.2.3.4/tcp/4001")
+	addrs := []ma.Multiaddr{
+		ma.StringCast("/ip4/178.62.158.247/tcp/4001/ipfs/QmSoLer265NRgSp2LA3dPaeykiS1J6DifTC88f5uVQKNAd"),
+		noP2p,
+		ma.StringCast("/ip4/128.199.219.111/tcp/4001/ipfs/QmSoLV4Bbm51jM9C4gDYZQ9Cy3U6aXMJDAbzgu2fzaDs64"),
+		nil,
+		ma.StringCast("/ipfs/QmSoLPppuBtQSGwKDZT2M73ULpjvfd3aZ6ha4oFGL1KrGM"),
+	}
+
+	infos, err := AddrInfosFromP2pAddrsSorted(addrs...)
+	errs, ok := err.(AddrErrors)
+	if !ok {
+		t.Fatalf("expected AddrErrors, got %v", err)
+	}
+	if len(errs) != 2 || !errs[0].Addr.Equal(noP2p) || errs[1].Addr != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if !errors.Is(errs[0], ErrInvalidAddr) {
+		t.Fatal("expected ErrInvalidAddr")
+	}
+
+	expected := []string{
+		"QmSoLPppuBtQSGwKDZT2M73ULpjvfd3aZ6ha4oFGL1KrGM",
+		"QmSoLV4Bbm51jM9C4gDYZQ9Cy3U6aXMJDAbzgu2fzaDs64",
+		"QmSoLer265NRgSp2LA3dPaeykiS1J6DifTC88f5uVQKNAd",
+	}
+	if len(infos) != len(expected) {
+		t.Fatalf("expected %d AddrInfos, got %d", len(expected), len(infos))
+	}
+	for i, info := range infos {
+		if info.ID.Pretty() != expected[i] {
+			t.Fatalf("expected %s at position %d, got %s", expected[i], i, info.ID)
+		}
+	}
+
+	if _, err := AddrInfosFromP2pAddrsSorted(addrs[0], addrs[2]); err != nil {
+		t.Fatal(err)
+	}
 }
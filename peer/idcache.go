@@ -0,0 +1,108 @@
+package peer
+
+import (
+	"container/list"
+	"sync"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+// DefaultIDCacheSize is the default number of entries of the cache used by
+// IDFromPublicKeyCached.
+const DefaultIDCacheSize = 1024
+
+var idCache = newIDLRU(DefaultIDCacheSize)
+
+// SetIDCacheSize resizes the cache used by IDFromPublicKeyCached, dropping
+// all cached entries. A size of zero or less disables caching.
+func SetIDCacheSize(n int) {
+	idCache.reset(n)
+}
+
+// IDFromPublicKeyCached is like IDFromPublicKey, but caches the derived peer
+// IDs, keyed on the serialized public key. This avoids re-hashing keys that
+// are seen over and over, e.g. when validating records.
+//
+// See SetIDCacheSize to tune the size of the cache.
+func IDFromPublicKeyCached(pk ic.PubKey) (ID, error) {
+	b, err := pk.Bytes()
+	if err != nil {
+		return "", err
+	}
+	// The derivation depends on AdvancedEnableInlining, so remember it
+	// to never return an ID derived under a different setting.
+	inlining := AdvancedEnableInlining
+
+	if id, ok := idCache.get(b, inlining); ok {
+		return id, nil
+	}
+	id, err := IDFromPublicKey(pk)
+	if err != nil {
+		return "", err
+	}
+	idCache.add(b, inlining, id)
+	return id, nil
+}
+
+// idLRU is a minimal, concurrency-safe LRU cache of peer IDs.
+type idLRU struct {
+	lk    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type idLRUEntry struct {
+	key      string
+	inlining bool
+	id       ID
+}
+
+func newIDLRU(size int) *idLRU {
+	c := new(idLRU)
+	c.reset(size)
+	return c
+}
+
+func (c *idLRU) reset(size int) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	c.size = size
+	c.order = list.New()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *idLRU) get(key []byte, inlining bool) (ID, bool) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	e, ok := c.items[string(key)]
+	if !ok || e.Value.(*idLRUEntry).inlining != inlining {
+		return "", false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*idLRUEntry).id, true
+}
+
+func (c *idLRU) add(key []byte, inlining bool, id ID) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.items[string(key)]; ok {
+		entry := e.Value.(*idLRUEntry)
+		entry.inlining, entry.id = inlining, id
+		c.order.MoveToFront(e)
+		return
+	}
+	entry := &idLRUEntry{key: string(key), inlining: inlining, id: id}
+	c.items[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*idLRUEntry).key)
+	}
+}
@@ -0,0 +1,98 @@
+package peer_test
+
+import (
+	"sync"
+	"testing"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestIDFromPublicKeyCached(t *testing.T) {
+	defer SetIDCacheSize(DefaultIDCacheSize)
+	SetIDCacheSize(2)
+
+	var keys []ic.PubKey
+	for _, typ := range []int{ic.RSA, ic.Ed25519, ic.Secp256k1, ic.ECDSA} {
+		bits := 512
+		if typ == ic.RSA {
+			bits = 2048
+		}
+		_, pk, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, pk)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Cycle through more keys than the cache can hold, to exercise
+			// eviction.
+			for j := 0; j < 20; j++ {
+				pk := keys[j%len(keys)]
+				expected, err := IDFromPublicKey(pk)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				id, err := IDFromPublicKeyCached(pk)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if id != expected {
+					t.Errorf("cached ID %s doesn't match %s", id, expected)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Changing the inlining setting must not return IDs cached under the
+	// previous setting.
+	edKey := keys[1]
+	inlined, err := IDFromPublicKeyCached(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AdvancedEnableInlining = false
+	defer func() { AdvancedEnableInlining = true }()
+	hashed, err := IDFromPublicKeyCached(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inlined == hashed {
+		t.Fatal("expected a different ID with inlining disabled")
+	}
+	if !hashed.MatchesPublicKey(edKey) {
+		t.Fatal("ID doesn't match the public key")
+	}
+}
+
+func benchmarkIDFromPublicKey(b *testing.B, f func(ic.PubKey) (ID, error)) {
+	_, pk, err := test.RandTestKeyPair(ic.RSA, 2048)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f(pk); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIDFromPublicKey(b *testing.B) {
+	benchmarkIDFromPublicKey(b, IDFromPublicKey)
+}
+
+func BenchmarkIDFromPublicKeyCached(b *testing.B) {
+	benchmarkIDFromPublicKey(b, IDFromPublicKeyCached)
+}
@@ -9,8 +9,10 @@ import (
 
 	cid "github.com/ipfs/go-cid"
 	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/internal/peerid"
 	b58 "github.com/mr-tron/base58/base58"
 	mh "github.com/multiformats/go-multihash"
+	"github.com/multiformats/go-varint"
 )
 
 var (
@@ -33,7 +35,11 @@ var (
 // be set to false by default when an upgrade path is determined.
 var AdvancedEnableInlining = true
 
-const maxInlineKeyLength = 42
+const maxInlineKeyLength = peerid.MaxInlineKeyLength
+
+func init() {
+	peerid.InliningEnabled = func() bool { return AdvancedEnableInlining }
+}
 
 // ID is a libp2p peer identity.
 //
@@ -57,7 +63,12 @@ func (id ID) String() string {
 	return id.Pretty()
 }
 
-// ShortString prints out the peer ID.
+// ShortString returns a truncated form of the peer ID, made of the first two
+// and last six characters of its base58 encoding (e.g.
+// "<peer.ID Qm*Bz8yzq>"), for use in logs.
+//
+// The short form is ambiguous: it must never be used to compare or identify
+// peers.
 //
 // TODO(brian): ensure correctness at ID generation and
 // enforce this by only exposing functions that generate
@@ -104,6 +115,17 @@ func (id ID) ExtractPublicKey() (ic.PubKey, error) {
 	return pk, nil
 }
 
+// ExtractPublicKey returns the public key inlined in id, i.e. when id is an
+// identity multihash of the key, as is the case for Ed25519 keys. This makes
+// it possible to verify records signed by peers without having their key in
+// a peerstore.
+//
+// It returns ErrNoPublicKey if id is valid but doesn't inline the key, e.g.
+// because it is a SHA-256 multihash. It is equivalent to ID.ExtractPublicKey.
+func ExtractPublicKey(id ID) (ic.PubKey, error) {
+	return id.ExtractPublicKey()
+}
+
 // Validate checks if ID is empty or not.
 func (id ID) Validate() error {
 	if id == ID("") {
@@ -113,6 +135,109 @@ func (id ID) Validate() error {
 	return nil
 }
 
+// Validate checks that id is a well-formed peer ID: a multihash using either
+// the identity hash function (for inlined keys of at most 42 bytes) or
+// sha2-256.
+//
+// Unlike the ID.Validate method, which only checks that the ID isn't empty,
+// this is suitable for validating IDs received from untrusted sources.
+func Validate(id ID) error {
+	if id == "" {
+		return ErrEmptyPeerID
+	}
+	decoded, err := mh.Decode([]byte(id))
+	if err != nil {
+		return fmt.Errorf("invalid peer ID: %s", err)
+	}
+	switch decoded.Code {
+	case mh.IDENTITY:
+		if decoded.Length > maxInlineKeyLength {
+			return fmt.Errorf("invalid peer ID: inlined keys must be at most %d bytes", maxInlineKeyLength)
+		}
+	case mh.SHA2_256:
+	default:
+		return fmt.Errorf("invalid peer ID: unsupported hash function %s", mh.Codes[decoded.Code])
+	}
+	return nil
+}
+
+// IsValidString reports whether s is a valid encoded peer ID, either as a
+// base58 multihash or as a CID. See Validate for details.
+//
+// Base58-encoded peer IDs are decoded into a fixed-size buffer and validated
+// in place, without allocating. CIDs (and unusually long multihashes) are
+// still decoded with Decode, which allocates.
+func IsValidString(s string) bool {
+	if strings.HasPrefix(s, "Qm") || strings.HasPrefix(s, "1") {
+		var buf [maxB58PeerIDLen]byte
+		if b, ok := decodeB58Into(&buf, s); ok {
+			return validMultihash(b)
+		}
+	}
+	id, err := Decode(s)
+	return err == nil && Validate(id) == nil
+}
+
+// b58Alphabet is the bitcoin base58 alphabet, used to encode peer IDs.
+const b58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// maxB58PeerIDLen is the size of the buffer IsValidString decodes base58 peer
+// IDs into. It fits inlined keys and SHA-256 multihashes.
+const maxB58PeerIDLen = 64
+
+// decodeB58Into decodes the base58 string s into the end of buf, and returns
+// the decoded bytes. It returns false if s isn't valid base58, or if the
+// decoded bytes don't fit in buf.
+func decodeB58Into(buf *[maxB58PeerIDLen]byte, s string) ([]byte, bool) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	size := 0
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(b58Alphabet, s[i])
+		if carry < 0 {
+			return nil, false
+		}
+		j := 0
+		for ; j < size || carry != 0; j++ {
+			if j >= len(buf) {
+				return nil, false
+			}
+			idx := len(buf) - 1 - j
+			carry += 58 * int(buf[idx])
+			buf[idx] = byte(carry)
+			carry >>= 8
+		}
+		size = j
+	}
+	if size+zeros > len(buf) || size+zeros == 0 {
+		return nil, false
+	}
+	return buf[len(buf)-size-zeros:], true
+}
+
+// validMultihash reports whether b is a multihash that Validate accepts,
+// without decoding it into a new ID.
+func validMultihash(b []byte) bool {
+	code, n, err := varint.FromUvarint(b)
+	if err != nil {
+		return false
+	}
+	length, m, err := varint.FromUvarint(b[n:])
+	if err != nil || length != uint64(len(b)-n-m) {
+		return false
+	}
+	switch code {
+	case mh.IDENTITY:
+		return length <= maxInlineKeyLength
+	case mh.SHA2_256:
+		return true
+	default:
+		return false
+	}
+}
+
 // IDFromString casts a string to the ID type, and validates
 // the value to make sure it is a multihash.
 func IDFromString(s string) (ID, error) {
@@ -220,16 +345,40 @@ func ToCid(id ID) cid.Cid {
 
 // IDFromPublicKey returns the Peer ID corresponding to the public key pk.
 func IDFromPublicKey(pk ic.PubKey) (ID, error) {
+	hash, err := peerid.FromPublicKey(pk)
+	if err != nil {
+		return "", err
+	}
+	return ID(hash), nil
+}
+
+// IDFromPublicKeyWithHash returns the Peer ID corresponding to the public key
+// pk, hashed with the given multihash function instead of the one chosen by
+// IDFromPublicKey. hashCode must be mh.SHA2_256 or mh.IDENTITY, the latter
+// only for keys small enough to be inlined.
+//
+// Forcing mh.SHA2_256 for small keys yields IDs that always are SHA-256
+// multihashes, at the cost of interoperability: the resulting ID differs
+// from the one other peers derive from the same key (so ID.MatchesPublicKey
+// doesn't recognize it), and the public key can't be extracted from it (see
+// ID.ExtractPublicKey).
+func IDFromPublicKeyWithHash(pk ic.PubKey, hashCode uint64) (ID, error) {
+	if hashCode != mh.SHA2_256 && hashCode != mh.IDENTITY {
+		return "", fmt.Errorf("unsupported peer ID hash function %s", mh.Codes[hashCode])
+	}
 	b, err := pk.Bytes()
 	if err != nil {
 		return "", err
 	}
-	var alg uint64 = mh.SHA2_256
-	if AdvancedEnableInlining && len(b) <= maxInlineKeyLength {
-		alg = mh.IDENTITY
+	hash, err := mh.Sum(b, hashCode, -1)
+	if err != nil {
+		return "", err
+	}
+	id := ID(hash)
+	if err := Validate(id); err != nil {
+		return "", err
 	}
-	hash, _ := mh.Sum(b, alg, -1)
-	return ID(hash), nil
+	return id, nil
 }
 
 // IDFromPrivateKey returns the Peer ID corresponding to the secret key sk.
@@ -55,6 +55,10 @@ func (id *ID) UnmarshalJSON(data []byte) (err error) {
 	if err = json.Unmarshal(data, &v); err != nil {
 		return err
 	}
+	if v == "" {
+		*id = ""
+		return nil
+	}
 	*id, err = IDB58Decode(v)
 	return err
 }
@@ -65,7 +69,13 @@ func (id ID) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText restores the ID from its text encoding.
+//
+// An empty input restores the empty ID, so that empty IDs round-trip.
 func (id *ID) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*id = ""
+		return nil
+	}
 	pid, err := IDB58Decode(string(data))
 	if err != nil {
 		return err
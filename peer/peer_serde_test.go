@@ -1,6 +1,7 @@
 package peer_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/libp2p/go-libp2p-core/peer"
@@ -81,3 +82,62 @@ func TestTextMarshaler(t *testing.T) {
 		t.Error("expected equal ids in circular serde test")
 	}
 }
+
+func TestEmptyIDSerde(t *testing.T) {
+	var empty peer.ID
+
+	b, err := empty.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 0 {
+		t.Fatalf("expected the empty ID to marshal to an empty string, got %q", b)
+	}
+	id := peer.ID("garbage")
+	if err = id.UnmarshalText(b); err != nil {
+		t.Fatal(err)
+	}
+	if id != empty {
+		t.Error("expected the empty ID to round-trip through text")
+	}
+
+	b, err = json.Marshal(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `""` {
+		t.Fatalf("expected the empty ID to marshal to an empty JSON string, got %s", b)
+	}
+	id = peer.ID("garbage")
+	if err = json.Unmarshal(b, &id); err != nil {
+		t.Fatal(err)
+	}
+	if id != empty {
+		t.Error("expected the empty ID to round-trip through JSON")
+	}
+}
+
+func TestTextMarshalerInStruct(t *testing.T) {
+	id, err := RandPeerID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type config struct {
+		Peer peer.ID
+	}
+	b, err := json.Marshal(config{Peer: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"Peer":"` + id.String() + `"}`; string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+	var c config
+	if err = json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Peer != id {
+		t.Error("expected equal ids in circular serde test")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	cid "github.com/ipfs/go-cid"
 	ic "github.com/libp2p/go-libp2p-core/crypto"
 	. "github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/test"
@@ -198,6 +199,40 @@ func TestIDEncoding(t *testing.T) {
 	}
 }
 
+func TestCidRoundTripAllKeyTypes(t *testing.T) {
+	for _, typ := range ic.KeyTypes {
+		bits := 512
+		if typ == ic.RSA {
+			bits = 2048
+		}
+		_, pk, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := IDFromPublicKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := ToCid(id)
+		if c.Type() != cid.Libp2pKey {
+			t.Fatalf("%s: expected a libp2p-key CID, got codec %d", pk.Type(), c.Type())
+		}
+		id2, err := FromCid(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != id2 {
+			t.Fatalf("%s: peer ID didn't round-trip through CID", pk.Type())
+		}
+
+		// The same multihash with another codec must be rejected.
+		if _, err := FromCid(cid.NewCidV1(cid.Raw, c.Hash())); err == nil {
+			t.Fatalf("%s: expected FromCid to reject a raw CID", pk.Type())
+		}
+	}
+}
+
 func TestPublicKeyExtraction(t *testing.T) {
 	t.Skip("disabled until libp2p/go-libp2p-crypto#51 is fixed")
 	// Happy path
@@ -250,6 +285,148 @@ func TestPublicKeyExtraction(t *testing.T) {
 	}
 }
 
+func TestIDFromUncompressedSecp256k1Key(t *testing.T) {
+	_, pub, err := ic.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := pub.(*ic.Secp256k1PublicKey).RawUncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubNew, err := ic.UnmarshalSecp256k1PublicKeyUncompressed(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idNew, err := IDFromPublicKey(pubNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != idNew {
+		t.Fatalf("expected peer ID %s, got %s", id, idNew)
+	}
+}
+
+func TestIDFromPublicKeyWithHash(t *testing.T) {
+	_, edPub, err := ic.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rsaPub, err := ic.GenerateKeyPair(ic.RSA, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pk := range []ic.PubKey{edPub, rsaPub} {
+		id, err := IDFromPublicKeyWithHash(pk, mh.SHA2_256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := mh.Decode([]byte(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Code != mh.SHA2_256 {
+			t.Fatalf("expected a sha2-256 multihash, got %s", mh.Codes[decoded.Code])
+		}
+	}
+
+	// The default is unchanged: small keys are inlined.
+	id, err := IDFromPublicKey(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forced, _ := IDFromPublicKeyWithHash(edPub, mh.SHA2_256); forced == id || forced.MatchesPublicKey(edPub) {
+		t.Fatal("expected the forced ID to differ from the default one")
+	}
+	inlined, err := IDFromPublicKeyWithHash(edPub, mh.IDENTITY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != inlined {
+		t.Fatalf("expected %s, got %s", id, inlined)
+	}
+
+	if _, err := IDFromPublicKeyWithHash(rsaPub, mh.IDENTITY); err == nil {
+		t.Fatal("expected large keys not to be inlined")
+	}
+	if _, err := IDFromPublicKeyWithHash(edPub, mh.SHA2_512); err == nil {
+		t.Fatal("expected unsupported hash functions to be rejected")
+	}
+}
+
+func TestExtractPublicKey(t *testing.T) {
+	_, edPub, err := ic.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := IDFromPublicKey(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := ExtractPublicKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !edPub.Equals(pk) {
+		t.Fatal("extracted public key doesn't match")
+	}
+
+	// Keys hashed with SHA-256 aren't inlined.
+	hashedID, err := IDFromPublicKeyWithHash(edPub, mh.SHA2_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk, err := ExtractPublicKey(hashedID); err != ErrNoPublicKey || pk != nil {
+		t.Fatalf("expected ErrNoPublicKey, got %v", err)
+	}
+
+	if _, err := ExtractPublicKey(ID("")); err == nil {
+		t.Fatal("expected an error for an invalid ID")
+	}
+}
+
+func TestIDFromSeedIsStable(t *testing.T) {
+	var seed [32]byte
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	_, pk, err := ic.GenerateEd25519KeyFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "12D3KooWA4Xop1JaT3MHxwYMkCepYsv4iPVopMXwCz5iHYdBfeSB"; id.Pretty() != expected {
+		t.Fatalf("expected peer ID %s, got %s", expected, id.Pretty())
+	}
+}
+
+func TestShortString(t *testing.T) {
+	id, err := Decode("QmS3zcG7LhYZYSJMhyRZvTddvbNUqtt8BJpaSs6mi1K5Va")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := id.ShortString(); s != "<peer.ID Qm*i1K5Va>" {
+		t.Fatalf("unexpected short string %q", s)
+	}
+	if s := id.String(); s != "QmS3zcG7LhYZYSJMhyRZvTddvbNUqtt8BJpaSs6mi1K5Va" {
+		t.Fatalf("String() must return the full ID, got %q", s)
+	}
+
+	// IDs too short to be truncated are printed in full.
+	if s := ID("a").ShortString(); s != "<peer.ID 2g>" {
+		t.Fatalf("unexpected short string %q", s)
+	}
+}
+
 func TestValidate(t *testing.T) {
 	// Empty peer ID invalidates
 	err := ID("").Validate()
@@ -299,3 +476,83 @@ tGrTrmsynBjt2wKBgErdYe+zZ2Wo+wXQGAoZi4pfcwiw4a97Kdh0dx+WZz7acHms
 h+V20VRmEHm5h8WnJ/Wv5uK94t6NY17wzjQ7y2BN5mY5cA2cZAcpeqtv/N06tH4S
 cn1UEuRB8VpwkjaPUNZhqtYK40qff2OTdJy8taFtQiN7fz9euWTC78zjph2s
 `
+
+func TestValidateStrict(t *testing.T) {
+	if err := Validate(""); err != ErrEmptyPeerID {
+		t.Fatalf("expected ErrEmptyPeerID, got %v", err)
+	}
+
+	for _, typ := range []int{ic.RSA, ic.Ed25519} {
+		bits := 512
+		if typ == ic.RSA {
+			bits = 2048
+		}
+		_, pk, err := test.RandTestKeyPair(typ, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := IDFromPublicKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Validate(id); err != nil {
+			t.Fatal(err)
+		}
+		if !IsValidString(id.String()) {
+			t.Fatalf("expected %s to be valid", id)
+		}
+		if !IsValidString(ToCid(id).String()) {
+			t.Fatalf("expected the CID of %s to be valid", id)
+		}
+	}
+
+	sha1, _ := mh.Sum([]byte("foo"), mh.SHA1, -1)
+	longIdentity, _ := mh.Sum(make([]byte, 100), mh.IDENTITY, -1)
+	for _, id := range []ID{"not a multihash", ID(sha1), ID(longIdentity)} {
+		if err := Validate(id); err == nil {
+			t.Fatalf("expected %x to be invalid", []byte(id))
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"QmNotBase58!",
+		"bafkreifoybygix7fh3r3g5rqle3wcnhqldgdg4shzf4k3ulyw3gn7mabt4", // not a libp2p-key CID
+		b58.Encode(sha1),
+	} {
+		if IsValidString(s) {
+			t.Fatalf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestIsValidStringMatchesDecode(t *testing.T) {
+	sha256, _ := mh.Sum([]byte("foo"), mh.SHA2_256, -1)
+	identity, _ := mh.Sum(make([]byte, 42), mh.IDENTITY, -1)
+	longIdentity, _ := mh.Sum(make([]byte, 43), mh.IDENTITY, -1)
+	huge, _ := mh.Encode(make([]byte, 100), mh.SHA2_256)
+	truncated := b58.Encode(sha256[:len(sha256)-1])
+	for _, s := range []string{
+		b58.Encode(sha256),
+		b58.Encode(identity),
+		b58.Encode(longIdentity),
+		b58.Encode(huge),
+		truncated,
+		"1",
+		"11111",
+		"Qm",
+		"QmNotBase58!",
+		"1" + b58.Encode(sha256),
+	} {
+		id, err := Decode(s)
+		expected := err == nil && Validate(id) == nil
+		if valid := IsValidString(s); valid != expected {
+			t.Errorf("IsValidString(%q) = %t, expected %t", s, valid, expected)
+		}
+	}
+
+	encoded := b58.Encode(sha256)
+	if allocs := testing.AllocsPerRun(100, func() { IsValidString(encoded) }); allocs != 0 {
+		t.Errorf("expected IsValidString not to allocate for base58 peer IDs, got %f allocations", allocs)
+	}
+}
@@ -69,3 +69,51 @@ func (ps *Set) Peers() []ID {
 	ps.lk.Unlock()
 	return out
 }
+
+// Remove removes the given peer from the set, if present.
+func (ps *Set) Remove(p ID) {
+	ps.lk.Lock()
+	delete(ps.ps, p)
+	ps.lk.Unlock()
+}
+
+// Len returns the number of peers in the set. It's equivalent to Size.
+func (ps *Set) Len() int {
+	return ps.Size()
+}
+
+// ForEach calls f for every peer in the set. It iterates over a snapshot of
+// the set and doesn't hold the lock while calling f, so f may safely modify
+// the set. Iteration stops early if f returns false.
+func (ps *Set) ForEach(f func(ID) bool) {
+	for _, p := range ps.Peers() {
+		if !f(p) {
+			return
+		}
+	}
+}
+
+// Union returns a new (unlimited) set containing the peers of both a and b.
+func Union(a, b *Set) *Set {
+	out := NewSet()
+	for _, s := range []*Set{a, b} {
+		for _, p := range s.Peers() {
+			out.ps[p] = struct{}{}
+		}
+	}
+	return out
+}
+
+// Intersect returns a new (unlimited) set containing the peers that are in
+// both a and b.
+func Intersect(a, b *Set) *Set {
+	out := NewSet()
+	// Snapshot a instead of locking both sets at once, to avoid lock-order
+	// inversions when called concurrently with Intersect(b, a).
+	for _, p := range a.Peers() {
+		if b.Contains(p) {
+			out.ps[p] = struct{}{}
+		}
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package peer_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestSetOperations(t *testing.T) {
+	a := NewSet()
+	b := NewSet()
+	for _, p := range []ID{"a", "b", "c"} {
+		a.Add(p)
+	}
+	for _, p := range []ID{"b", "c", "d"} {
+		b.Add(p)
+	}
+
+	a.Remove("c")
+	a.Remove("not there")
+	if a.Contains("c") || a.Len() != 2 {
+		t.Fatal("expected c to be removed")
+	}
+
+	check := func(s *Set, expected ...ID) {
+		t.Helper()
+		peers := s.Peers()
+		sort.Sort(IDSlice(peers))
+		if len(peers) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, peers)
+		}
+		for i := range peers {
+			if peers[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, peers)
+			}
+		}
+	}
+	check(Union(a, b), "a", "b", "c", "d")
+	check(Intersect(a, b), "b")
+	check(Intersect(b, a), "b")
+
+	// ForEach must be able to modify the set it's iterating over.
+	count := 0
+	b.ForEach(func(p ID) bool {
+		b.Remove(p)
+		count++
+		return true
+	})
+	if count != 3 || b.Len() != 0 {
+		t.Fatal("expected ForEach to visit and remove all peers")
+	}
+
+	count = 0
+	a.ForEach(func(ID) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatal("expected ForEach to stop early")
+	}
+}
+
+func TestSetConcurrent(t *testing.T) {
+	a := NewSet()
+	b := NewSet()
+	peers := []ID{"a", "b", "c", "d", "e"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := peers[(i+j)%len(peers)]
+				a.Add(p)
+				b.TryAdd(p)
+				a.Contains(p)
+				Union(a, b)
+				Intersect(b, a)
+				a.ForEach(func(ID) bool { return true })
+				b.Remove(p)
+				a.Len()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if a.Len() != len(peers) {
+		t.Fatalf("expected %d peers, got %d", len(peers), a.Len())
+	}
+}
@@ -0,0 +1,85 @@
+package peerstore
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// DefaultLatencySamples is the default number of latency samples kept per
+// peer by LatencySamples.
+const DefaultLatencySamples = 128
+
+// LatencyPercentiles is implemented by Metrics that can report latency
+// percentiles, in addition to the moving average returned by LatencyEWMA.
+type LatencyPercentiles interface {
+	// LatencyPercentile returns the q-quantile (0 <= q <= 1) of the latency
+	// measurements recorded for the peer with RecordLatency, e.g. 0.99 for
+	// the 99th percentile. It returns 0 if no measurement was recorded.
+	LatencyPercentile(p peer.ID, q float64) time.Duration
+}
+
+// LatencySamples keeps a bounded window of the most recent latency
+// measurements of a peer, and computes percentiles over it. It can be used
+// by Metrics implementations to implement LatencyPercentiles, with a memory
+// footprint bounded by the window size.
+//
+// LatencySamples is safe for concurrent use.
+type LatencySamples struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// NewLatencySamples creates a LatencySamples keeping the size most recent
+// measurements. If size isn't positive, DefaultLatencySamples is used.
+func NewLatencySamples(size int) *LatencySamples {
+	if size <= 0 {
+		size = DefaultLatencySamples
+	}
+	return &LatencySamples{samples: make([]time.Duration, size)}
+}
+
+// Record adds a measurement, evicting the oldest one if the window is full.
+func (s *LatencySamples) Record(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples[s.next] = d
+	s.next++
+	if s.next == len(s.samples) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+// Percentile returns the q-quantile of the recorded measurements, using the
+// nearest-rank method. q is clamped to [0, 1], and a NaN q is treated as 0.
+// It returns 0 if no measurement was recorded.
+func (s *LatencySamples) Percentile(q float64) time.Duration {
+	s.mu.Lock()
+	n := s.next
+	if s.full {
+		n = len(s.samples)
+	}
+	if n == 0 {
+		s.mu.Unlock()
+		return 0
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, s.samples[:n])
+	s.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if q != q || q <= 0 {
+		return sorted[0]
+	}
+	rank := int(math.Ceil(q * float64(n)))
+	if rank > n {
+		rank = n
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,54 @@
+package peerstore
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestLatencySamplesPercentile(t *testing.T) {
+	s := NewLatencySamples(1000)
+	if p := s.Percentile(0.5); p != 0 {
+		t.Fatalf("expected 0 without samples, got %s", p)
+	}
+
+	// Record 1ms to 1000ms, in random order.
+	for _, i := range rand.Perm(1000) {
+		s.Record(time.Duration(i+1) * time.Millisecond)
+	}
+	for _, tc := range []struct {
+		q        float64
+		expected time.Duration
+	}{
+		{0, time.Millisecond},
+		{0.5, 500 * time.Millisecond},
+		{0.95, 950 * time.Millisecond},
+		{0.99, 990 * time.Millisecond},
+		{1, 1000 * time.Millisecond},
+		{2, 1000 * time.Millisecond},
+		{-1, time.Millisecond},
+		{math.NaN(), time.Millisecond},
+	} {
+		if p := s.Percentile(tc.q); p != tc.expected {
+			t.Errorf("expected percentile %f to be %s, got %s", tc.q, tc.expected, p)
+		}
+	}
+}
+
+func TestLatencySamplesWindow(t *testing.T) {
+	s := NewLatencySamples(10)
+	for i := 0; i < 10; i++ {
+		s.Record(time.Second)
+	}
+	// The old samples are evicted as new ones come in.
+	for i := 0; i < 10; i++ {
+		s.Record(time.Millisecond)
+	}
+	if p := s.Percentile(1); p != time.Millisecond {
+		t.Fatalf("expected old samples to be evicted, got a max of %s", p)
+	}
+	if len(s.samples) != 10 {
+		t.Fatalf("expected memory to stay bounded, got %d samples", len(s.samples))
+	}
+}
@@ -183,6 +183,19 @@ type CertifiedAddrBook interface {
 	GetPeerRecord(p peer.ID) *record.Envelope
 }
 
+// ErrNoCertifiedRecord is returned by CertifiedTTLRefresher.RefreshCertifiedTTL
+// when no certified record is stored for the peer.
+var ErrNoCertifiedRecord = errors.New("no certified record for peer")
+
+// CertifiedTTLRefresher is implemented by CertifiedAddrBooks that can extend
+// the TTL of stored certified addresses without a new signed record.
+type CertifiedTTLRefresher interface {
+	// RefreshCertifiedTTL sets the TTL of the certified addresses of the
+	// given peer to ttl, keeping the stored Envelope. It returns
+	// ErrNoCertifiedRecord if no certified record is stored for the peer.
+	RefreshCertifiedTTL(p peer.ID, ttl time.Duration) error
+}
+
 // GetCertifiedAddrBook is a helper to "upcast" an AddrBook to a
 // CertifiedAddrBook by using type assertion. If the given AddrBook
 // is also a CertifiedAddrBook, it will be returned, and the ok return
@@ -196,6 +209,83 @@ func GetCertifiedAddrBook(ab AddrBook) (cab CertifiedAddrBook, ok bool) {
 	return cab, ok
 }
 
+// AddrInfoTTL bundles the addresses of a peer with the TTL to store them
+// with. It is used to add addresses in bulk, see AddAddrsBatch.
+type AddrInfoTTL struct {
+	ID    peer.ID
+	Addrs []ma.Multiaddr
+	TTL   time.Duration
+}
+
+// BatchAddrBook is implemented by AddrBooks that can add the addresses of
+// many peers at once more efficiently than by calling AddAddrs for each of
+// them, e.g. by taking their write lock only once.
+type BatchAddrBook interface {
+	// AddAddrsBatch is equivalent to calling AddAddrs for each entry, in
+	// order.
+	AddAddrsBatch(entries []AddrInfoTTL)
+}
+
+// AddAddrsBatch adds the addresses of all the given entries to the AddrBook.
+// If the AddrBook is a BatchAddrBook, its AddAddrsBatch method is used.
+// Otherwise, AddAddrs is called for each entry.
+func AddAddrsBatch(ab AddrBook, entries []AddrInfoTTL) {
+	if bab, ok := ab.(BatchAddrBook); ok {
+		bab.AddAddrsBatch(entries)
+		return
+	}
+	for _, e := range entries {
+		ab.AddAddrs(e.ID, e.Addrs, e.TTL)
+	}
+}
+
+// AddrTTL is an address of a peer, along with its remaining TTL.
+type AddrTTL struct {
+	Addr ma.Multiaddr
+	// TTL is the time left, at the time of the query, before the address
+	// expires. It is not an absolute expiry time. Permanent addresses (and
+	// those stored with ConnectedAddrTTL) report the TTL they were stored
+	// with.
+	TTL time.Duration
+}
+
+// AddrTTLBook is implemented by AddrBooks that can report the remaining TTL
+// of the addresses they store.
+type AddrTTLBook interface {
+	// AddrsWithTTL returns all the known (and valid) addresses of the given
+	// peer, like Addrs, along with their remaining TTLs.
+	AddrsWithTTL(p peer.ID) []AddrTTL
+}
+
+// AddrsWithTTL returns the addresses of the given peer along with their
+// remaining TTLs, and true, if the AddrBook is an AddrTTLBook. Otherwise, it
+// returns nil and false, as the TTLs are unknown.
+func AddrsWithTTL(ab AddrBook, p peer.ID) ([]AddrTTL, bool) {
+	if tb, ok := ab.(AddrTTLBook); ok {
+		return tb.AddrsWithTTL(p), true
+	}
+	return nil, false
+}
+
+// AddrSubscriber is implemented by AddrBooks that can notify subscribers of
+// changes to the addresses of a given peer.
+type AddrSubscriber interface {
+	// SubscribeAddrs subscribes to changes of the addresses of the given
+	// peer. Whenever AddAddrs, SetAddrs, ClearAddrs (or any other method
+	// changing the addresses of the peer) is called, the full, new set of
+	// addresses of the peer is sent on the returned channel.
+	//
+	// Delivery is coalesced: writers never block on subscribers, and if the
+	// subscriber hasn't consumed the previous address set by the time the
+	// addresses change again, only the latest set is delivered. Subscribers
+	// are thus guaranteed to eventually receive the latest state, but may
+	// miss intermediate ones.
+	//
+	// The returned function cancels the subscription and closes the
+	// channel. It must be called once the subscriber is done.
+	SubscribeAddrs(p peer.ID) (<-chan []ma.Multiaddr, func())
+}
+
 // KeyBook tracks the keys of Peers.
 type KeyBook interface {
 	// PubKey stores the public key of a peer.
@@ -216,6 +306,24 @@ type KeyBook interface {
 	PeersWithKeys() peer.IDSlice
 }
 
+// KeySubscriber is implemented by KeyBooks that can notify subscribers when
+// the public key of a peer becomes known, e.g. so that records received
+// before the key was known can be verified.
+type KeySubscriber interface {
+	// SubscribeKeyAdded subscribes to the addition of public keys. The ID
+	// of the peer is sent on the returned channel whenever AddPubKey stores
+	// a public key that wasn't previously known. Only the peer ID is sent,
+	// so that subscribers don't retain keys they don't need; use PubKey to
+	// retrieve it.
+	//
+	// Writers never block on subscribers: if the channel is full, the
+	// notification is dropped.
+	//
+	// The returned function cancels the subscription and closes the
+	// channel. It must be called once the subscriber is done.
+	SubscribeKeyAdded() (<-chan peer.ID, func())
+}
+
 // Metrics is just an object that tracks metrics
 // across a set of peers.
 type Metrics interface {
@@ -243,3 +351,68 @@ type ProtoBook interface {
 	// If the returned error is not nil, the result is indeterminate.
 	FirstSupportedProtocol(peer.ID, ...string) (string, error)
 }
+
+// ProtocolIndex is implemented by ProtoBooks maintaining a reverse index from
+// protocols to the peers supporting them.
+type ProtocolIndex interface {
+	// PeersForProtocol returns the peers known to support the given
+	// protocol. The result is a snapshot: it isn't affected by later
+	// changes to the ProtoBook.
+	PeersForProtocol(proto string) peer.IDSlice
+}
+
+// PeersForProtocol returns the peers in the peerstore known to support the
+// given protocol, as a snapshot. If the peerstore is a ProtocolIndex, its
+// PeersForProtocol method is used; otherwise, this scans all the peers.
+func PeersForProtocol(ps Peerstore, proto string) peer.IDSlice {
+	if idx, ok := ps.(ProtocolIndex); ok {
+		return idx.PeersForProtocol(proto)
+	}
+	var peers peer.IDSlice
+	for _, p := range ps.Peers() {
+		supported, err := ps.SupportsProtocols(p, proto)
+		if err == nil && len(supported) > 0 {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// PeerSnapshot is everything a Peerstore knows about a peer, at a given point
+// in time. It isn't affected by later changes to the Peerstore.
+type PeerSnapshot struct {
+	ID        peer.ID
+	Addrs     []ma.Multiaddr
+	PubKey    ic.PubKey // nil if unknown
+	Protocols []string
+	// Latency is the exponentially-weighted moving average of the
+	// latency to the peer, or 0 if unknown.
+	Latency time.Duration
+}
+
+// PeerSnapshotter is implemented by Peerstores that can read everything they
+// know about a peer consistently, e.g. under a single lock.
+type PeerSnapshotter interface {
+	// PeerSnapshot returns a point-in-time snapshot of the information
+	// stored about the given peer.
+	PeerSnapshot(p peer.ID) PeerSnapshot
+}
+
+// PeerSnapshotOf returns a point-in-time snapshot of the information stored
+// about the given peer. If the peerstore is a PeerSnapshotter, its
+// PeerSnapshot method is used, and the snapshot is consistent. Otherwise,
+// each sub-book is queried separately, and concurrent changes may be partially
+// reflected.
+func PeerSnapshotOf(ps Peerstore, p peer.ID) PeerSnapshot {
+	if s, ok := ps.(PeerSnapshotter); ok {
+		return s.PeerSnapshot(p)
+	}
+	protos, _ := ps.GetProtocols(p)
+	return PeerSnapshot{
+		ID:        p,
+		Addrs:     ps.Addrs(p),
+		PubKey:    ps.PubKey(p),
+		Protocols: protos,
+		Latency:   ps.LatencyEWMA(p),
+	}
+}
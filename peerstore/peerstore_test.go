@@ -0,0 +1,178 @@
+package peerstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// mapAddrBook is a minimal AddrBook, ignoring TTLs.
+type mapAddrBook struct {
+	addrs map[peer.ID][]ma.Multiaddr
+	calls int
+}
+
+var _ AddrBook = (*mapAddrBook)(nil)
+
+func newMapAddrBook() *mapAddrBook {
+	return &mapAddrBook{addrs: make(map[peer.ID][]ma.Multiaddr)}
+}
+
+func (ab *mapAddrBook) AddAddr(p peer.ID, addr ma.Multiaddr, ttl time.Duration) {
+	ab.AddAddrs(p, []ma.Multiaddr{addr}, ttl)
+}
+
+func (ab *mapAddrBook) AddAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	ab.calls++
+	ab.addrs[p] = append(ab.addrs[p], addrs...)
+}
+
+func (ab *mapAddrBook) SetAddr(p peer.ID, addr ma.Multiaddr, ttl time.Duration) {
+	ab.SetAddrs(p, []ma.Multiaddr{addr}, ttl)
+}
+
+func (ab *mapAddrBook) SetAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	ab.addrs[p] = append([]ma.Multiaddr(nil), addrs...)
+}
+
+func (ab *mapAddrBook) UpdateAddrs(peer.ID, time.Duration, time.Duration) {}
+func (ab *mapAddrBook) Addrs(p peer.ID) []ma.Multiaddr                    { return ab.addrs[p] }
+func (ab *mapAddrBook) ClearAddrs(p peer.ID)                              { delete(ab.addrs, p) }
+
+func (ab *mapAddrBook) AddrStream(context.Context, peer.ID) <-chan ma.Multiaddr {
+	return nil
+}
+
+func (ab *mapAddrBook) PeersWithAddrs() peer.IDSlice {
+	var peers peer.IDSlice
+	for p := range ab.addrs {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+type batchAddrBook struct {
+	*mapAddrBook
+	batches int
+}
+
+func (ab *batchAddrBook) AddAddrsBatch(entries []AddrInfoTTL) {
+	ab.batches++
+	for _, e := range entries {
+		ab.addrs[e.ID] = append(ab.addrs[e.ID], e.Addrs...)
+	}
+}
+
+func TestAddAddrsBatch(t *testing.T) {
+	entries := []AddrInfoTTL{
+		{ID: "a", Addrs: []ma.Multiaddr{ma.StringCast("/ip4/1.2.3.4/tcp/1")}, TTL: time.Hour},
+		{ID: "b", Addrs: []ma.Multiaddr{ma.StringCast("/ip4/1.2.3.4/tcp/2")}, TTL: time.Hour},
+	}
+
+	ab := newMapAddrBook()
+	AddAddrsBatch(ab, entries)
+	if ab.calls != 2 {
+		t.Fatalf("expected AddAddrs to be called for each entry, got %d calls", ab.calls)
+	}
+	if len(ab.Addrs("a")) != 1 || len(ab.Addrs("b")) != 1 {
+		t.Fatal("expected addresses to be added")
+	}
+
+	bab := &batchAddrBook{mapAddrBook: newMapAddrBook()}
+	AddAddrsBatch(bab, entries)
+	if bab.batches != 1 || bab.calls != 0 {
+		t.Fatalf("expected a single batch, got %d batches and %d calls", bab.batches, bab.calls)
+	}
+	if len(bab.Addrs("a")) != 1 || len(bab.Addrs("b")) != 1 {
+		t.Fatal("expected addresses to be added")
+	}
+}
+
+type ttlAddrBook struct {
+	*mapAddrBook
+}
+
+func (ab *ttlAddrBook) AddrsWithTTL(p peer.ID) []AddrTTL {
+	res := make([]AddrTTL, 0, len(ab.addrs[p]))
+	for _, a := range ab.addrs[p] {
+		res = append(res, AddrTTL{Addr: a, TTL: time.Minute})
+	}
+	return res
+}
+
+func TestAddrsWithTTL(t *testing.T) {
+	addr := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+
+	ab := newMapAddrBook()
+	ab.AddAddr("a", addr, time.Hour)
+	if addrs, ok := AddrsWithTTL(ab, "a"); ok || addrs != nil {
+		t.Fatalf("expected no TTLs, got %v", addrs)
+	}
+
+	tab := &ttlAddrBook{mapAddrBook: ab}
+	addrs, ok := AddrsWithTTL(tab, "a")
+	if !ok {
+		t.Fatal("expected the AddrBook to report TTLs")
+	}
+	if len(addrs) != 1 || !addrs[0].Addr.Equal(addr) || addrs[0].TTL != time.Minute {
+		t.Fatalf("unexpected addresses: %v", addrs)
+	}
+}
+
+// stubPeerstore implements the parts of Peerstore read by PeerSnapshotOf.
+type stubPeerstore struct {
+	Peerstore
+	ab        *mapAddrBook
+	pubKey    ic.PubKey
+	protocols []string
+	latency   time.Duration
+}
+
+func (ps *stubPeerstore) Addrs(p peer.ID) []ma.Multiaddr         { return ps.ab.Addrs(p) }
+func (ps *stubPeerstore) PubKey(peer.ID) ic.PubKey               { return ps.pubKey }
+func (ps *stubPeerstore) GetProtocols(peer.ID) ([]string, error) { return ps.protocols, nil }
+func (ps *stubPeerstore) LatencyEWMA(peer.ID) time.Duration      { return ps.latency }
+
+type snapshotPeerstore struct {
+	*stubPeerstore
+}
+
+func (ps *snapshotPeerstore) PeerSnapshot(p peer.ID) PeerSnapshot {
+	return PeerSnapshot{ID: p, Latency: time.Hour}
+}
+
+func TestPeerSnapshotOf(t *testing.T) {
+	_, pub, err := ic.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	ps := &stubPeerstore{
+		ab:        newMapAddrBook(),
+		pubKey:    pub,
+		protocols: []string{"/foo/1.0.0"},
+		latency:   time.Millisecond,
+	}
+	ps.ab.AddAddr("a", addr, time.Hour)
+
+	s := PeerSnapshotOf(ps, "a")
+	if s.ID != "a" || len(s.Addrs) != 1 || !s.Addrs[0].Equal(addr) {
+		t.Fatalf("unexpected addresses in snapshot: %v", s)
+	}
+	if s.PubKey == nil || !s.PubKey.Equals(pub) {
+		t.Fatal("expected the public key in the snapshot")
+	}
+	if len(s.Protocols) != 1 || s.Protocols[0] != "/foo/1.0.0" || s.Latency != time.Millisecond {
+		t.Fatalf("unexpected snapshot: %v", s)
+	}
+
+	s = PeerSnapshotOf(&snapshotPeerstore{ps}, "a")
+	if s.Latency != time.Hour || s.Addrs != nil {
+		t.Fatalf("expected the PeerSnapshotter to be used, got %v", s)
+	}
+}
@@ -0,0 +1,137 @@
+package peerstore
+
+import (
+	"fmt"
+	"time"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pb "github.com/libp2p/go-libp2p-core/peerstore/pb"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// SnapshotVersion is the version of the snapshot format produced by
+// MarshalSnapshot.
+const SnapshotVersion = 1
+
+// Snapshotter is implemented by Peerstores that can be persisted to, and
+// restored from, a single blob. It complements datastore-backed peerstores
+// for environments that prefer to manage persistence themselves.
+//
+// Implementations should use MarshalSnapshot and UnmarshalSnapshot, so that
+// snapshots are portable across implementations.
+type Snapshotter interface {
+	// Snapshot serializes the addresses (with their remaining TTLs), public
+	// keys and protocols of all the peers in the peerstore.
+	Snapshot() ([]byte, error)
+
+	// LoadSnapshot adds the contents of a snapshot to the peerstore.
+	// Addresses that expired since the snapshot was taken are dropped.
+	LoadSnapshot([]byte) error
+}
+
+// SnapshotAddr is an address stored in a snapshot, along with its remaining
+// TTL.
+type SnapshotAddr struct {
+	Addr ma.Multiaddr
+	TTL  time.Duration
+}
+
+// SnapshotPeer is the state of a peer stored in a snapshot.
+type SnapshotPeer struct {
+	ID        peer.ID
+	Addrs     []SnapshotAddr
+	PubKey    ic.PubKey // nil if unknown
+	Protocols []string
+}
+
+// MarshalSnapshot serializes the given peers into a versioned snapshot.
+func MarshalSnapshot(peers []SnapshotPeer) ([]byte, error) {
+	return marshalSnapshot(peers, time.Now())
+}
+
+// UnmarshalSnapshot deserializes a snapshot produced by MarshalSnapshot. The
+// TTLs of the returned addresses are reduced by the time elapsed since the
+// snapshot was taken, and expired addresses are dropped. Permanent addresses
+// (see PermanentAddrTTL and ConnectedAddrTTL) never expire.
+func UnmarshalSnapshot(data []byte) ([]SnapshotPeer, error) {
+	return unmarshalSnapshot(data, time.Now())
+}
+
+func marshalSnapshot(peers []SnapshotPeer, now time.Time) ([]byte, error) {
+	msg := &pb.Snapshot{
+		Version:   SnapshotVersion,
+		Timestamp: now.UnixNano(),
+		Peers:     make([]*pb.Snapshot_Peer, 0, len(peers)),
+	}
+	for _, p := range peers {
+		pp := &pb.Snapshot_Peer{
+			Id:        []byte(p.ID),
+			Addrs:     make([]*pb.Snapshot_AddrInfo, 0, len(p.Addrs)),
+			Protocols: p.Protocols,
+		}
+		for _, a := range p.Addrs {
+			pp.Addrs = append(pp.Addrs, &pb.Snapshot_AddrInfo{
+				Multiaddr: a.Addr.Bytes(),
+				Ttl:       int64(a.TTL),
+			})
+		}
+		if p.PubKey != nil {
+			pk, err := ic.MarshalPublicKey(p.PubKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal public key of peer %s: %w", p.ID, err)
+			}
+			pp.PublicKey = pk
+		}
+		msg.Peers = append(msg.Peers, pp)
+	}
+	return msg.Marshal()
+}
+
+func unmarshalSnapshot(data []byte, now time.Time) ([]SnapshotPeer, error) {
+	var msg pb.Snapshot
+	if err := msg.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	if msg.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", msg.Version)
+	}
+
+	elapsed := now.Sub(time.Unix(0, msg.Timestamp))
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	peers := make([]SnapshotPeer, 0, len(msg.Peers))
+	for _, pp := range msg.Peers {
+		id, err := peer.IDFromBytes(pp.Id)
+		if err != nil {
+			return nil, err
+		}
+		p := SnapshotPeer{ID: id, Protocols: pp.Protocols}
+		for _, a := range pp.Addrs {
+			ttl := time.Duration(a.Ttl)
+			if ttl < ConnectedAddrTTL {
+				ttl -= elapsed
+				if ttl <= 0 {
+					continue
+				}
+			}
+			addr, err := ma.NewMultiaddrBytes(a.Multiaddr)
+			if err != nil {
+				return nil, err
+			}
+			p.Addrs = append(p.Addrs, SnapshotAddr{Addr: addr, TTL: ttl})
+		}
+		if len(pp.PublicKey) > 0 {
+			pk, err := ic.UnmarshalPublicKey(pp.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal public key of peer %s: %w", id, err)
+			}
+			p.PubKey = pk
+		}
+		peers = append(peers, p)
+	}
+	return peers, nil
+}
@@ -0,0 +1,87 @@
+package peerstore
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	_, pub, err := ic.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	long := ma.StringCast("/ip4/1.2.3.4/tcp/2")
+	permanent := ma.StringCast("/ip4/1.2.3.4/tcp/3")
+	peers := []SnapshotPeer{{
+		ID: id,
+		Addrs: []SnapshotAddr{
+			{Addr: short, TTL: time.Minute},
+			{Addr: long, TTL: time.Hour},
+			{Addr: permanent, TTL: PermanentAddrTTL},
+		},
+		PubKey:    pub,
+		Protocols: []string{"/foo/1.0.0", "/bar/1.0.0"},
+	}}
+
+	now := time.Now()
+	data, err := marshalSnapshot(peers, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := unmarshalSnapshot(data, now.Add(10*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("expected 1 peer, got %d", len(loaded))
+	}
+	p := loaded[0]
+	if p.ID != id {
+		t.Fatalf("expected peer %s, got %s", id, p.ID)
+	}
+	if !p.PubKey.Equals(pub) {
+		t.Fatal("public key doesn't match")
+	}
+	if len(p.Protocols) != 2 || p.Protocols[0] != "/foo/1.0.0" || p.Protocols[1] != "/bar/1.0.0" {
+		t.Fatalf("unexpected protocols %v", p.Protocols)
+	}
+
+	// The short-lived address expired in the meantime.
+	if len(p.Addrs) != 2 {
+		t.Fatalf("expected 2 addresses, got %v", p.Addrs)
+	}
+	if !p.Addrs[0].Addr.Equal(long) || p.Addrs[0].TTL != 50*time.Minute {
+		t.Fatalf("expected %s with a TTL of 50m, got %s with %s", long, p.Addrs[0].Addr, p.Addrs[0].TTL)
+	}
+	if !p.Addrs[1].Addr.Equal(permanent) || p.Addrs[1].TTL != PermanentAddrTTL {
+		t.Fatalf("expected %s to remain permanent, got %s with %s", permanent, p.Addrs[1].Addr, p.Addrs[1].TTL)
+	}
+}
+
+func TestSnapshotVersion(t *testing.T) {
+	data, err := MarshalSnapshot(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalSnapshot(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// version = 2
+	if _, err := UnmarshalSnapshot([]byte{0x08, 0x02}); err == nil {
+		t.Fatal("expected an unknown snapshot version to be rejected")
+	}
+}
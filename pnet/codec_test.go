@@ -120,3 +120,23 @@ func testDecodeBin(t *testing.T, windows bool) {
 	}
 
 }
+
+func TestFingerprint(t *testing.T) {
+	b := bufWithBase("/base16/", false)
+	for i := 0; i < 32; i++ {
+		b.WriteString("FF")
+	}
+	psk, err := DecodeV1PSK(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fp := Fingerprint(psk); fp != "a03225d07ecde875" {
+		t.Fatalf("unexpected fingerprint %s", fp)
+	}
+
+	other := make(PSK, 32)
+	if Fingerprint(other) == Fingerprint(psk) {
+		t.Fatal("expected different keys to have different fingerprints")
+	}
+}
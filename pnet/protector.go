@@ -1,7 +1,48 @@
 // Package pnet provides interfaces for private networking in libp2p.
 package pnet
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+)
+
+// fingerprintDomain separates PSK fingerprints from other hashes of the key.
+const fingerprintDomain = "libp2p-pnet-psk-fingerprint:"
+
 // A PSK enables private network implementation to be transparent in libp2p.
 // It is used to ensure that peers can only establish connections to other peers
 // that are using the same PSK.
 type PSK []byte
+
+// Fingerprint returns a short, stable fingerprint of the PSK, which operators
+// can compare across nodes to check that they share the same key without
+// exposing it.
+//
+// The fingerprint is the hex encoding of the first 8 bytes of the SHA-256
+// hash of the PSK, prefixed by a fixed domain string.
+func Fingerprint(psk PSK) string {
+	h := sha256.New()
+	h.Write([]byte(fingerprintDomain))
+	h.Write(psk)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// ProtectedConn is a marker interface implemented by the connections returned
+// by private network protectors, i.e. connections that are protected by a
+// PSK.
+type ProtectedConn interface {
+	net.Conn
+
+	// IsPrivateNetwork reports whether the connection is protected by a
+	// PSK. Protectors should always return true.
+	IsPrivateNetwork() bool
+}
+
+// IsProtected reports whether c is protected by a private network protector,
+// without exposing any key material. It is mostly useful in diagnostics and
+// tests, to check that protection wasn't accidentally bypassed.
+func IsProtected(c net.Conn) bool {
+	pc, ok := c.(ProtectedConn)
+	return ok && pc.IsPrivateNetwork()
+}
@@ -0,0 +1,25 @@
+package pnet
+
+import (
+	"net"
+	"testing"
+)
+
+type protectedConn struct {
+	net.Conn
+}
+
+func (protectedConn) IsPrivateNetwork() bool { return true }
+
+func TestIsProtected(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	if IsProtected(c1) {
+		t.Fatal("expected a plain connection not to be protected")
+	}
+	if !IsProtected(protectedConn{c1}) {
+		t.Fatal("expected a wrapped connection to be protected")
+	}
+}
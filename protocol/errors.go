@@ -0,0 +1,7 @@
+package protocol
+
+import "errors"
+
+// ErrNoSupportedProtocols is returned (possibly wrapped) when negotiating a
+// stream with a peer that supports none of the proposed protocols.
+var ErrNoSupportedProtocols = errors.New("peer supports none of the requested protocols")
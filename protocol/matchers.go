@@ -0,0 +1,33 @@
+package protocol
+
+import "strings"
+
+// ExactMatcher returns a match function, suitable for
+// Router.AddHandlerWithFunc, matching only the given protocol ID.
+func ExactMatcher(id ID) func(string) bool {
+	return func(proto string) bool {
+		return proto == string(id)
+	}
+}
+
+// PrefixMatcher returns a match function, suitable for
+// Router.AddHandlerWithFunc, matching the given prefix and all the protocol
+// IDs below it. Matching is done on whole path segments: the prefix "/foo"
+// matches "/foo" and "/foo/1.0.0", but not "/foobar".
+func PrefixMatcher(prefix string) func(string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(proto string) bool {
+		return proto == prefix || strings.HasPrefix(proto, prefix+"/")
+	}
+}
+
+// AddPrefixHandler registers handler on the router for the given prefix
+// and all the protocol IDs below it (see PrefixMatcher).
+//
+// As with any handler added with a match function, if several registered
+// handlers match an incoming protocol ID, e.g. because of overlapping
+// prefixes, the router invokes the first one registered. Register more
+// specific prefixes first.
+func AddPrefixHandler(r Router, prefix string, handler HandlerFunc) {
+	r.AddHandlerWithFunc(prefix, PrefixMatcher(prefix), handler)
+}
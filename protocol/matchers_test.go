@@ -0,0 +1,92 @@
+package protocol
+
+import (
+	"io"
+	"testing"
+)
+
+// testRouter is a minimal Router, invoking the first registered handler
+// matching a protocol.
+type testRouter struct {
+	handlers []testHandler
+}
+
+type testHandler struct {
+	protocol string
+	match    func(string) bool
+	handler  HandlerFunc
+}
+
+func (r *testRouter) AddHandler(protocol string, handler HandlerFunc) {
+	r.AddHandlerWithFunc(protocol, ExactMatcher(ID(protocol)), handler)
+}
+
+func (r *testRouter) AddHandlerWithFunc(protocol string, match func(string) bool, handler HandlerFunc) {
+	r.handlers = append(r.handlers, testHandler{protocol, match, handler})
+}
+
+func (r *testRouter) RemoveHandler(protocol string) {}
+func (r *testRouter) Protocols() []string          { return nil }
+
+func (r *testRouter) handle(protocol string) bool {
+	for _, h := range r.handlers {
+		if h.match(protocol) {
+			h.handler(protocol, nil)
+			return true
+		}
+	}
+	return false
+}
+
+func TestMatchers(t *testing.T) {
+	exact := ExactMatcher("/foo/1.0.0")
+	if !exact("/foo/1.0.0") || exact("/foo/1.0.0/bar") || exact("/foo") {
+		t.Fatal("unexpected exact matching")
+	}
+
+	for _, prefix := range []string{"/foo", "/foo/"} {
+		match := PrefixMatcher(prefix)
+		for _, p := range []string{"/foo", "/foo/", "/foo/1.0.0", "/foo/bar/1.0.0"} {
+			if !match(p) {
+				t.Errorf("expected prefix %s to match %s", prefix, p)
+			}
+		}
+		for _, p := range []string{"/foobar", "/fo", "/bar/foo"} {
+			if match(p) {
+				t.Errorf("expected prefix %s not to match %s", prefix, p)
+			}
+		}
+	}
+}
+
+func TestAddPrefixHandlerPrecedence(t *testing.T) {
+	r := new(testRouter)
+	var called string
+	handler := func(name string) HandlerFunc {
+		return func(string, io.ReadWriteCloser) error {
+			called = name
+			return nil
+		}
+	}
+
+	// The most specific prefix is registered first.
+	AddPrefixHandler(r, "/foo/bar", handler("bar"))
+	AddPrefixHandler(r, "/foo", handler("foo"))
+
+	for p, expected := range map[string]string{
+		"/foo/bar/1.0.0": "bar",
+		"/foo/baz/1.0.0": "foo",
+		"/foo":           "foo",
+	} {
+		called = ""
+		if !r.handle(p) {
+			t.Fatalf("expected %s to be handled", p)
+		}
+		if called != expected {
+			t.Errorf("expected %s to be handled by %s, got %s", p, expected, called)
+		}
+	}
+	if r.handle("/foobar") {
+		t.Error("expected /foobar not to be handled")
+	}
+}
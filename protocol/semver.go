@@ -0,0 +1,101 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+)
+
+// parseConstraint parses a version constraint into a function checking
+// whether a version satisfies it.
+func parseConstraint(c string) (func(semver.Version) bool, error) {
+	switch {
+	case c == "*" || c == "x":
+		return func(semver.Version) bool { return true }, nil
+	case strings.HasPrefix(c, "^"):
+		min, err := parseConstraintVersion(c, c[1:])
+		if err != nil {
+			return nil, err
+		}
+		return func(v semver.Version) bool {
+			if v.LessThan(*min) || v.Major != min.Major {
+				return false
+			}
+			// For 0.x versions, minor versions are considered breaking.
+			return min.Major != 0 || v.Minor == min.Minor
+		}, nil
+	case strings.HasPrefix(c, "~"):
+		min, err := parseConstraintVersion(c, c[1:])
+		if err != nil {
+			return nil, err
+		}
+		return func(v semver.Version) bool {
+			return !v.LessThan(*min) && v.Major == min.Major && v.Minor == min.Minor
+		}, nil
+	case strings.HasSuffix(c, ".x"):
+		// "1.x" and "1.2.x" are parsed as 1.0.0 and 1.2.0, of which only the
+		// given components are compared.
+		prefix := strings.TrimSuffix(c, ".x")
+		n := strings.Count(prefix, ".") + 1
+		if n > 2 {
+			return nil, fmt.Errorf("invalid version constraint %q", c)
+		}
+		want, err := parseConstraintVersion(c, prefix+strings.Repeat(".0", 3-n))
+		if err != nil {
+			return nil, err
+		}
+		return func(v semver.Version) bool {
+			return v.Major == want.Major && (n < 2 || v.Minor == want.Minor)
+		}, nil
+	default:
+		want, err := parseConstraintVersion(c, c)
+		if err != nil {
+			return nil, err
+		}
+		return func(v semver.Version) bool { return v.Equal(*want) }, nil
+	}
+}
+
+func parseConstraintVersion(c, v string) (*semver.Version, error) {
+	version, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %s", c, err)
+	}
+	return version, nil
+}
+
+// SemverMatcher returns a match function, suitable for
+// Router.AddHandlerWithFunc, matching the protocol IDs made of base followed
+// by a semantic version satisfying constraint, e.g. "/myproto/1.2.3" for the
+// base "/myproto".
+//
+// The supported constraints are:
+//
+//  * "^1.2.3": compatible versions, i.e. >= 1.2.3 with the same major version
+//    (or the same minor version for 0.x versions).
+//  * "~1.2.3": >= 1.2.3 with the same major and minor versions.
+//  * "1.x", "1.2.x": versions with the given prefix.
+//  * "1.2.3": exactly the given version.
+//  * "*": any version.
+//
+// Versions are parsed with github.com/coreos/go-semver, like the ones of
+// helpers.MultistreamSemverMatcher: they must be of the form
+// MAJOR.MINOR.PATCH, optionally followed by pre-release and build metadata.
+// Pre-release versions are ordered as specified by semver, e.g. 1.3.0-rc1
+// satisfies "^1.2.0" but 1.2.0-rc1 doesn't. Protocol IDs whose version can't
+// be parsed don't match.
+func SemverMatcher(base, constraint string) (func(string) bool, error) {
+	satisfies, err := parseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(base, "/") + "/"
+	return func(proto string) bool {
+		if !strings.HasPrefix(proto, prefix) {
+			return false
+		}
+		v, err := semver.NewVersion(proto[len(prefix):])
+		return err == nil && satisfies(*v)
+	}, nil
+}
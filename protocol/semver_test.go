@@ -0,0 +1,62 @@
+package protocol
+
+import (
+	"testing"
+)
+
+func TestSemverMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{{
+		constraint: "^1.2.0",
+		match:      []string{"/myproto/1.2.0", "/myproto/1.2.3", "/myproto/1.10.0", "/myproto/1.3.0-rc1"},
+		noMatch:    []string{"/myproto/1.1.9", "/myproto/2.0.0", "/myproto/0.9.0", "/myproto/1.2.0-rc1"},
+	}, {
+		constraint: "^0.2.1",
+		match:      []string{"/myproto/0.2.1", "/myproto/0.2.5"},
+		noMatch:    []string{"/myproto/0.3.0", "/myproto/0.2.0", "/myproto/1.0.0"},
+	}, {
+		constraint: "~1.2.3",
+		match:      []string{"/myproto/1.2.3", "/myproto/1.2.9"},
+		noMatch:    []string{"/myproto/1.3.0", "/myproto/1.2.2"},
+	}, {
+		constraint: "1.x",
+		match:      []string{"/myproto/1.0.0", "/myproto/1.5.2"},
+		noMatch:    []string{"/myproto/2.0.0", "/myproto/0.1.0", "/myproto/1"},
+	}, {
+		constraint: "1.2.x",
+		match:      []string{"/myproto/1.2.0", "/myproto/1.2.7"},
+		noMatch:    []string{"/myproto/1.3.0", "/myproto/1.2"},
+	}, {
+		constraint: "1.0.0",
+		match:      []string{"/myproto/1.0.0", "/myproto/1.0.0+build1"},
+		noMatch:    []string{"/myproto/1.0.1", "/myproto/1.0.0-rc1", "/myproto/1.0"},
+	}, {
+		constraint: "*",
+		match:      []string{"/myproto/0.0.1", "/myproto/42.0.0", "/myproto/1.0.0-rc1"},
+		noMatch:    []string{"/myproto/latest", "/myproto", "/other/1.0.0", "/myproto/1.0.0.0"},
+	}} {
+		match, err := SemverMatcher("/myproto", tc.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range tc.match {
+			if !match(p) {
+				t.Errorf("expected %s to satisfy %s", p, tc.constraint)
+			}
+		}
+		for _, p := range tc.noMatch {
+			if match(p) {
+				t.Errorf("expected %s not to satisfy %s", p, tc.constraint)
+			}
+		}
+	}
+
+	for _, c := range []string{"", "^", "^a.b.c", "1.2.3.x", "1.2", "~1.2", "1-rc.x", ">=1.0.0"} {
+		if _, err := SemverMatcher("/myproto", c); err == nil {
+			t.Errorf("expected constraint %q to be rejected", c)
+		}
+	}
+}
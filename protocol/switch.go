@@ -45,6 +45,11 @@ type Router interface {
 	// Note that the Router may be able to handle protocol IDs not
 	// included in this list if handlers were added with match functions
 	// using AddHandlerWithFunc.
+	//
+	// For handlers added with AddHandlerWithFunc (or AddPrefixHandler),
+	// the protocol string passed at registration is listed, even though
+	// it may be a prefix or a base protocol rather than a concrete ID.
+	// Use ConvertFromStrings to get protocol.IDs.
 	Protocols() []string
 }
 
@@ -2,9 +2,15 @@ package record
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p-core/crypto"
 	pb "github.com/libp2p/go-libp2p-core/record/pb"
@@ -32,9 +38,17 @@ type Envelope struct {
 	// The envelope payload.
 	RawPayload []byte
 
-	// The signature of the domain string :: type hint :: payload.
+	// The time after which the envelope is no longer valid. The zero value
+	// means the envelope never expires.
+	Expiry time.Time
+
+	// The signature of the domain string :: type hint :: payload [:: expiry].
 	signature []byte
 
+	// the registry used to look up the Record type of the payload. nil means
+	// DefaultRegistry.
+	registry *Registry
+
 	// the unmarshalled payload as a Record, cached on first access via the Record accessor method
 	cached         Record
 	unmarshalError error
@@ -44,10 +58,60 @@ type Envelope struct {
 var ErrEmptyDomain = errors.New("envelope domain must not be empty")
 var ErrEmptyPayloadType = errors.New("payloadType must not be empty")
 var ErrInvalidSignature = errors.New("invalid signature or incorrect domain")
+var ErrEnvelopeExpired = errors.New("envelope has expired")
+
+// ErrDomainMismatch is matched (with errors.Is) by the errors returned when
+// consuming an Envelope that was validly signed, but for another domain than
+// the expected one.
+//
+// As the domain is covered by the signature but not stored in the Envelope,
+// this can only be detected when a Record type is registered for the
+// Envelope's payload type, in the Registry used to consume it, and the
+// signature verifies for that type's domain. Otherwise, a domain mismatch
+// can't be told apart from an invalid signature, and only ErrInvalidSignature
+// is matched. Errors matching ErrDomainMismatch also match
+// ErrInvalidSignature.
+var ErrDomainMismatch = errors.New("envelope domain mismatch")
+
+// signatureError is returned when an Envelope's signature doesn't verify. err
+// is the error returned by the key's Verify method, if any. signedDomain is the
+// domain the Envelope was actually signed for, if it could be determined.
+type signatureError struct {
+	err          error
+	signedDomain string
+}
+
+func (e *signatureError) Error() string {
+	if e.signedDomain != "" {
+		return fmt.Sprintf("%s: %s (signed for domain %q)", ErrInvalidSignature, ErrDomainMismatch, e.signedDomain)
+	}
+	if e.err != nil {
+		return fmt.Sprintf("%s: %s", ErrInvalidSignature, e.err)
+	}
+	return ErrInvalidSignature.Error()
+}
+
+func (e *signatureError) Is(target error) bool {
+	return target == ErrInvalidSignature || (target == ErrDomainMismatch && e.signedDomain != "")
+}
+
+func (e *signatureError) Unwrap() error {
+	return e.err
+}
 
 // Seal marshals the given Record, places the marshaled bytes inside an Envelope,
 // and signs with the given private key.
 func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
+	return SealWithExpiry(rec, privateKey, time.Time{})
+}
+
+// SealWithExpiry is like Seal, but the resulting Envelope expires at the given
+// time, with a one second precision. Once expired, ConsumeEnvelope and
+// ConsumeTypedEnvelope reject the Envelope with ErrEnvelopeExpired.
+//
+// The expiry is covered by the signature. A zero expiry means the Envelope
+// never expires, just like with Seal.
+func SealWithExpiry(rec Record, privateKey crypto.PrivKey, expiry time.Time) (*Envelope, error) {
 	payload, err := rec.MarshalRecord()
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling record: %v", err)
@@ -63,7 +127,8 @@ func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
 		return nil, ErrEmptyPayloadType
 	}
 
-	unsigned, err := makeUnsigned(domain, payloadType, payload)
+	expirySecs := expiryToUnix(expiry)
+	unsigned, err := makeUnsigned(domain, payloadType, payload, expirySecs)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +143,42 @@ func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
 		PublicKey:   privateKey.GetPublic(),
 		PayloadType: payloadType,
 		RawPayload:  payload,
+		Expiry:      expiryFromUnix(expirySecs),
+		signature:   sig,
+	}, nil
+}
+
+// MakeEnvelopeWithSigner creates an Envelope holding the given payload, like
+// Seal does for a Record, but signed by the sign callback instead of a
+// crypto.PrivKey. This allows signing with keys that can't be exported, e.g.
+// kept in a hardware security module.
+//
+// sign is passed the exact bytes Seal would sign, and must return their
+// signature by the private key matching pub, as PrivKey.Sign would. The
+// resulting Envelope is thus verifiable by any consumer.
+func MakeEnvelopeWithSigner(pub crypto.PubKey, sign func(msg []byte) ([]byte, error), domain string, payloadType, payload []byte) (*Envelope, error) {
+	if domain == "" {
+		return nil, ErrEmptyDomain
+	}
+	if len(payloadType) == 0 {
+		return nil, ErrEmptyPayloadType
+	}
+
+	unsigned, err := makeUnsigned(domain, payloadType, payload, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Put(unsigned)
+
+	sig, err := sign(unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		PublicKey:   pub,
+		PayloadType: payloadType,
+		RawPayload:  payload,
 		signature:   sig,
 	}, nil
 }
@@ -111,11 +212,23 @@ func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
 // If the Envelope signature is valid, but no Record type is registered for the Envelope's
 // PayloadType, ErrPayloadTypeNotRegistered will be returned, along with the Envelope and
 // a nil Record.
+//
+// The returned errors wrap ErrInvalidSignature, ErrDomainMismatch, ErrEnvelopeExpired and
+// ErrPayloadTypeNotRegistered as appropriate, so they can be told apart with errors.Is.
+// See ErrDomainMismatch for when a domain mismatch can be detected.
+//
+// Record types are looked up in the shared DefaultRegistry. Use Registry.Consume to
+// use another Registry.
 func ConsumeEnvelope(data []byte, domain string) (envelope *Envelope, rec Record, err error) {
+	return consumeEnvelope(DefaultRegistry, data, domain)
+}
+
+func consumeEnvelope(registry *Registry, data []byte, domain string) (envelope *Envelope, rec Record, err error) {
 	e, err := UnmarshalEnvelope(data)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed when unmarshalling the envelope: %w", err)
 	}
+	e.registry = registry
 
 	err = e.validate(domain)
 	if err != nil {
@@ -129,6 +242,85 @@ func ConsumeEnvelope(data []byte, domain string) (envelope *Envelope, rec Record
 	return e, rec, nil
 }
 
+// ConsumeEnvelopeBatch is like ConsumeEnvelope, for many serialized Envelopes
+// at once. The signatures are verified as a batch (see crypto.BatchVerify),
+// which is considerably faster when all Envelopes are signed with Ed25519 keys.
+//
+// The results are positional: envelopes[i], records[i] and errs[i] are the
+// result of consuming serialized[i], as ConsumeEnvelope would have returned
+// them. An invalid Envelope doesn't affect the others.
+func ConsumeEnvelopeBatch(serialized [][]byte, domain string) (envelopes []*Envelope, records []Record, errs []error) {
+	return consumeEnvelopeBatch(DefaultRegistry, serialized, domain)
+}
+
+func consumeEnvelopeBatch(registry *Registry, serialized [][]byte, domain string) (envelopes []*Envelope, records []Record, errs []error) {
+	envelopes = make([]*Envelope, len(serialized))
+	records = make([]Record, len(serialized))
+	errs = make([]error, len(serialized))
+
+	var (
+		idx  []int
+		pubs []crypto.PubKey
+		msgs [][]byte
+		sigs [][]byte
+	)
+	defer func() {
+		for _, unsigned := range msgs {
+			pool.Put(unsigned)
+		}
+	}()
+
+	for i, data := range serialized {
+		e, err := UnmarshalEnvelope(data)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed when unmarshalling the envelope: %w", err)
+			continue
+		}
+		e.registry = registry
+		envelopes[i] = e
+
+		unsigned, err := makeUnsigned(domain, e.PayloadType, e.RawPayload, expiryToUnix(e.Expiry))
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to validate envelope: %w", err)
+			continue
+		}
+
+		idx = append(idx, i)
+		pubs = append(pubs, e.PublicKey)
+		msgs = append(msgs, unsigned)
+		sigs = append(sigs, e.signature)
+	}
+
+	valid, err := crypto.BatchVerify(pubs, msgs, sigs)
+	if err != nil {
+		// The slices always have the same length, so this shouldn't happen;
+		// fail the envelopes that couldn't be verified rather than panicking.
+		for _, i := range idx {
+			errs[i] = fmt.Errorf("failed to validate envelope: %w", err)
+		}
+		return envelopes, records, errs
+	}
+
+	for j, i := range idx {
+		e := envelopes[i]
+		if !valid[j] {
+			errs[i] = fmt.Errorf("failed to validate envelope: %w", &signatureError{signedDomain: e.signedDomain(domain)})
+			continue
+		}
+		if e.expired() {
+			errs[i] = fmt.Errorf("failed to validate envelope: %w", ErrEnvelopeExpired)
+			continue
+		}
+		rec, err := e.Record()
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to unmarshal envelope payload: %w", err)
+			continue
+		}
+		records[i] = rec
+	}
+	return envelopes, records, errs
+}
+
 // ConsumeTypedEnvelope unmarshals a serialized Envelope and validates its
 // signature. If validation fails, an error is returned, along with the unmarshalled
 // envelope so it can be inspected.
@@ -150,6 +342,8 @@ func ConsumeEnvelope(data []byte, domain string) (envelope *Envelope, rec Record
 // cases, including when the envelope signature is invalid, both the Envelope and an error will
 // be returned. This allows you to inspect the unmarshalled but invalid Envelope. As a result,
 // you must not assume that any non-nil Envelope returned from this function is valid.
+//
+// As with ConsumeEnvelope, the returned errors can be inspected with errors.Is.
 func ConsumeTypedEnvelope(data []byte, destRecord Record) (envelope *Envelope, err error) {
 	e, err := UnmarshalEnvelope(data)
 	if err != nil {
@@ -171,6 +365,9 @@ func ConsumeTypedEnvelope(data []byte, destRecord Record) (envelope *Envelope, e
 
 // UnmarshalEnvelope unmarshals a serialized Envelope protobuf message,
 // without validating its contents. Most users should use ConsumeEnvelope.
+//
+// Both compressed (see MarshalCompressed) and uncompressed Envelopes are
+// accepted.
 func UnmarshalEnvelope(data []byte) (*Envelope, error) {
 	var e pb.Envelope
 	if err := proto.Unmarshal(data, &e); err != nil {
@@ -182,10 +379,16 @@ func UnmarshalEnvelope(data []byte) (*Envelope, error) {
 		return nil, err
 	}
 
+	payload, err := decompressPayload(e.Compression, e.Payload)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Envelope{
 		PublicKey:   key,
 		PayloadType: e.PayloadType,
-		RawPayload:  e.Payload,
+		RawPayload:  payload,
+		Expiry:      expiryFromUnix(e.Expiry),
 		signature:   e.Signature,
 	}, nil
 }
@@ -193,36 +396,140 @@ func UnmarshalEnvelope(data []byte) (*Envelope, error) {
 // Marshal returns a byte slice containing a serialized protobuf representation
 // of a Envelope.
 func (e *Envelope) Marshal() ([]byte, error) {
+	return e.marshal(pb.CompressionType_NONE)
+}
+
+// MarshalCompressed is like Marshal, but gzip-compresses the payload on the
+// wire. The signature, domain and payload type are unaffected: the signature
+// covers the uncompressed payload, and RawPayload is decompressed again by
+// UnmarshalEnvelope.
+func (e *Envelope) MarshalCompressed() ([]byte, error) {
+	return e.marshal(pb.CompressionType_GZIP)
+}
+
+// marshal serializes the Envelope, with its payload compressed as specified.
+// It is shared by Marshal and MarshalCompressed, so that both wire formats
+// only differ by the payload encoding.
+func (e *Envelope) marshal(compression pb.CompressionType) ([]byte, error) {
 	key, err := crypto.PublicKeyToProto(e.PublicKey)
 	if err != nil {
 		return nil, err
 	}
+	payload, err := compressPayload(compression, e.RawPayload)
+	if err != nil {
+		return nil, err
+	}
 
 	msg := pb.Envelope{
 		PublicKey:   key,
 		PayloadType: e.PayloadType,
-		Payload:     e.RawPayload,
+		Payload:     payload,
 		Signature:   e.signature,
+		Expiry:      expiryToUnix(e.Expiry),
+		Compression: compression,
 	}
 	return proto.Marshal(&msg)
 }
 
 // Equal returns true if the other Envelope has the same public key,
-// payload, payload type, and signature. This implies that they were also
-// created with the same domain string.
+// payload, payload type, expiry and signature. This implies that they were
+// also created with the same domain string.
 func (e *Envelope) Equal(other *Envelope) bool {
 	if other == nil {
 		return e == nil
 	}
 	return e.PublicKey.Equals(other.PublicKey) &&
 		bytes.Equal(e.PayloadType, other.PayloadType) &&
+		e.Expiry.Equal(other.Expiry) &&
 		bytes.Equal(e.signature, other.signature) &&
 		bytes.Equal(e.RawPayload, other.RawPayload)
 }
 
+// EnvelopesEqualBytes reports whether two serialized Envelopes carry the same
+// signature and payload type. It scans the top-level protobuf fields in
+// place, without unmarshalling the Envelopes, reconstructing their public keys
+// or decompressing their payloads, which makes it suitable for dedup caches.
+//
+// As the signature covers the domain, payload type, payload and expiry, two
+// valid Envelopes with the same signature are equal in the sense of
+// Envelope.Equal, regardless of field ordering or payload compression.
+// However, the payload itself isn't compared: an invalid Envelope copying the
+// signature of a valid one compares equal to it. Dedup caches must thus only
+// hold Envelopes that were validated (e.g. with ConsumeEnvelope), lest an
+// attacker get a legitimate Envelope dropped as a duplicate.
+//
+// Malformed inputs, and Envelopes without a signature, are never equal.
+func EnvelopesEqualBytes(a, b []byte) bool {
+	sigA, typeA, ok := envelopeDedupFields(a)
+	if !ok {
+		return false
+	}
+	sigB, typeB, ok := envelopeDedupFields(b)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(sigA, sigB) && bytes.Equal(typeA, typeB)
+}
+
+// Field numbers of the Envelope protobuf message read by envelopeDedupFields.
+const (
+	envelopePayloadTypeField = 2
+	envelopeSignatureField   = 5
+)
+
+// envelopeDedupFields returns the signature and payload type of a serialized
+// Envelope, as sub-slices of data. As in protobuf, the last occurrence of a
+// field wins. It returns false if data isn't a well-formed protobuf message or
+// has no signature.
+func envelopeDedupFields(data []byte) (sig, payloadType []byte, ok bool) {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, nil, false
+		}
+		data = data[n:]
+
+		switch key & 7 {
+		case 0: // varint
+			if _, n = binary.Uvarint(data); n <= 0 {
+				return nil, nil, false
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return nil, nil, false
+			}
+			data = data[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return nil, nil, false
+			}
+			value := data[n : n+int(l)]
+			data = data[n+int(l):]
+			switch key >> 3 {
+			case envelopePayloadTypeField:
+				payloadType = value
+			case envelopeSignatureField:
+				sig = value
+			}
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return nil, nil, false
+			}
+			data = data[4:]
+		default:
+			return nil, nil, false
+		}
+	}
+	return sig, payloadType, len(sig) > 0
+}
+
 // Record returns the Envelope's payload unmarshalled as a Record.
 // The concrete type of the returned Record depends on which Record
 // type was registered for the Envelope's PayloadType - see record.RegisterType.
+// Envelopes obtained from Registry.Consume use that Registry, all others use
+// DefaultRegistry.
 //
 // Once unmarshalled, the Record is cached for future access.
 func (e *Envelope) Record() (Record, error) {
@@ -230,7 +537,11 @@ func (e *Envelope) Record() (Record, error) {
 		if e.cached != nil {
 			return
 		}
-		e.cached, e.unmarshalError = unmarshalRecordPayload(e.PayloadType, e.RawPayload)
+		registry := e.registry
+		if registry == nil {
+			registry = DefaultRegistry
+		}
+		e.cached, e.unmarshalError = registry.unmarshalRecordPayload(e.PayloadType, e.RawPayload)
 	})
 	return e.cached, e.unmarshalError
 }
@@ -246,29 +557,65 @@ func (e *Envelope) TypedRecord(dest Record) error {
 	return dest.UnmarshalRecord(e.RawPayload)
 }
 
-// validate returns nil if the envelope signature is valid for the given 'domain',
-// or an error if signature validation fails.
+// validate returns nil if the envelope signature is valid for the given 'domain'
+// and the envelope hasn't expired, or an error otherwise.
 func (e *Envelope) validate(domain string) error {
-	unsigned, err := makeUnsigned(domain, e.PayloadType, e.RawPayload)
+	unsigned, err := makeUnsigned(domain, e.PayloadType, e.RawPayload, expiryToUnix(e.Expiry))
 	if err != nil {
 		return err
 	}
 	defer pool.Put(unsigned)
 
 	valid, err := e.PublicKey.Verify(unsigned, e.signature)
-	if err != nil {
-		return fmt.Errorf("failed while verifying signature: %w", err)
+	if err != nil || !valid {
+		return &signatureError{err: err, signedDomain: e.signedDomain(domain)}
 	}
-	if !valid {
-		return ErrInvalidSignature
+	if e.expired() {
+		return ErrEnvelopeExpired
 	}
 	return nil
 }
 
+// signedDomain is called when the signature doesn't verify for domain. It
+// returns the domain of the Record type registered for the Envelope's payload
+// type if it differs from domain and the signature verifies for it, and ""
+// otherwise. This costs at most one more signature verification, on the
+// failure path only.
+func (e *Envelope) signedDomain(domain string) string {
+	registry := e.registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	rec, err := registry.blankRecordForPayloadType(e.PayloadType)
+	if err != nil {
+		return ""
+	}
+	other := rec.Domain()
+	if other == "" || other == domain {
+		return ""
+	}
+	unsigned, err := makeUnsigned(other, e.PayloadType, e.RawPayload, expiryToUnix(e.Expiry))
+	if err != nil {
+		return ""
+	}
+	defer pool.Put(unsigned)
+	if valid, err := e.PublicKey.Verify(unsigned, e.signature); err != nil || !valid {
+		return ""
+	}
+	return other
+}
+
+func (e *Envelope) expired() bool {
+	return !e.Expiry.IsZero() && time.Now().After(e.Expiry)
+}
+
 // makeUnsigned is a helper function that prepares a buffer to sign or verify.
 // It returns a byte slice from a pool. The caller MUST return this slice to the
 // pool.
-func makeUnsigned(domain string, payloadType []byte, payload []byte) ([]byte, error) {
+//
+// The expiry (in unix seconds) is only appended when non-zero, so envelopes
+// without an expiry are signed exactly like before expiries were introduced.
+func makeUnsigned(domain string, payloadType []byte, payload []byte, expiry uint64) ([]byte, error) {
 	var (
 		fields = [][]byte{[]byte(domain), payloadType, payload}
 
@@ -278,6 +625,10 @@ func makeUnsigned(domain string, payloadType []byte, payload []byte) ([]byte, er
 		flen = make([][]byte, len(fields))
 		size = 0
 	)
+	if expiry != 0 {
+		fields = append(fields, varint.ToUvarint(expiry))
+		flen = append(flen, nil)
+	}
 
 	for i, f := range fields {
 		l := len(f)
@@ -295,3 +646,72 @@ func makeUnsigned(domain string, payloadType []byte, payload []byte) ([]byte, er
 
 	return b[:s], nil
 }
+
+// maxDecompressedPayloadSize bounds the size of decompressed payloads, so
+// that a small compressed envelope can't make us allocate unbounded memory.
+const maxDecompressedPayloadSize = 4 << 20
+
+// compressPayload encodes payload for the wire, as specified by compression.
+// It is the inverse of decompressPayload.
+func compressPayload(compression pb.CompressionType, payload []byte) ([]byte, error) {
+	switch compression {
+	case pb.CompressionType_NONE:
+		return payload, nil
+	case pb.CompressionType_GZIP:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown payload compression: %s", compression)
+	}
+}
+
+func decompressPayload(compression pb.CompressionType, payload []byte) ([]byte, error) {
+	switch compression {
+	case pb.CompressionType_NONE:
+		return payload, nil
+	case pb.CompressionType_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress payload: %w", err)
+		}
+		defer r.Close()
+		out, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedPayloadSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress payload: %w", err)
+		}
+		if len(out) > maxDecompressedPayloadSize {
+			return nil, fmt.Errorf("decompressed payload exceeds %d bytes", maxDecompressedPayloadSize)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown payload compression: %s", compression)
+	}
+}
+
+// expiryToUnix converts an expiry to its wire representation, in seconds since
+// the unix epoch. The zero time (never expires) maps to 0.
+func expiryToUnix(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	if t.Unix() <= 0 {
+		// Already expired, but 0 would mean "never".
+		return 1
+	}
+	return uint64(t.Unix())
+}
+
+// expiryFromUnix is the inverse of expiryToUnix.
+func expiryFromUnix(secs uint64) time.Time {
+	if secs == 0 || secs > math.MaxInt64 {
+		return time.Time{}
+	}
+	return time.Unix(int64(secs), 0)
+}
@@ -0,0 +1,59 @@
+package record
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/internal/peerid"
+
+	b58 "github.com/mr-tron/base58/base58"
+)
+
+// envelopeJSON is the JSON representation of an Envelope, see MarshalJSON.
+type envelopeJSON struct {
+	PeerID      string
+	PayloadType string
+	RawPayload  []byte
+	Expiry      *time.Time `json:",omitempty"`
+	Signature   []byte
+}
+
+// MarshalJSON returns a JSON representation of the Envelope, for human
+// inspection (e.g. in logs): the base58-encoded peer ID of the signer, the
+// payload type as a string, and the base64-encoded payload and signature.
+// The peer ID is empty if the Envelope has no public key (e.g. if it was only
+// partially built).
+//
+// It is not a canonical serialization, and can't be unmarshalled back into
+// an Envelope, as it doesn't hold the public key. Marshal remains the
+// authoritative encoding for the wire.
+func (e *Envelope) MarshalJSON() ([]byte, error) {
+	id, err := signerID(e)
+	if err != nil {
+		return nil, err
+	}
+	out := envelopeJSON{
+		PeerID:      id,
+		PayloadType: string(e.PayloadType),
+		RawPayload:  e.RawPayload,
+		Signature:   e.signature,
+	}
+	if !e.Expiry.IsZero() {
+		out.Expiry = &e.Expiry
+	}
+	return json.Marshal(&out)
+}
+
+// signerID returns the base58-encoded peer ID of the Envelope's signer, as
+// peer.IDFromPublicKey derives it, or an empty string if the Envelope has no
+// public key. The peer package can't be used here, as it depends on this one.
+func signerID(e *Envelope) (string, error) {
+	if e.PublicKey == nil {
+		return "", nil
+	}
+	hash, err := peerid.FromPublicKey(e.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return b58.Encode(hash), nil
+}
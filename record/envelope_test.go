@@ -2,15 +2,21 @@ package record_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
 	. "github.com/libp2p/go-libp2p-core/record"
 	pb "github.com/libp2p/go-libp2p-core/record/pb"
 	"github.com/libp2p/go-libp2p-core/test"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/multiformats/go-varint"
 )
 
 type simpleRecord struct {
@@ -312,3 +318,518 @@ func alterMessageAndMarshal(t *testing.T, envelope *Envelope, alterMsg func(*pb.
 
 	return serialized
 }
+
+func TestEnvelopeExpiry(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!"}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+	RegisterType(&simpleRecord{})
+
+	// not yet expired
+	expiry := time.Now().Add(time.Hour)
+	envelope, err := SealWithExpiry(rec, priv, expiry)
+	test.AssertNilError(t, err)
+	serialized, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	deserialized, _, err := ConsumeEnvelope(serialized, rec.Domain())
+	test.AssertNilError(t, err)
+	if deserialized.Expiry.Unix() != expiry.Unix() {
+		t.Errorf("expected expiry %s, got %s", expiry, deserialized.Expiry)
+	}
+	if !envelope.Equal(deserialized) {
+		t.Error("round-trip serde results in unequal envelope structures")
+	}
+	_, err = ConsumeTypedEnvelope(serialized, &simpleRecord{})
+	test.AssertNilError(t, err)
+
+	// the expiry is covered by the signature
+	for _, alteredExpiry := range []uint64{0, uint64(expiry.Add(time.Hour).Unix())} {
+		alteredExpiry := alteredExpiry
+		altered := alterMessageAndMarshal(t, envelope, func(msg *pb.Envelope) {
+			msg.Expiry = alteredExpiry
+		})
+		_, _, err = ConsumeEnvelope(altered, rec.Domain())
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("expected ErrInvalidSignature after altering the expiry, got %v", err)
+		}
+	}
+
+	// expired
+	envelope, err = SealWithExpiry(rec, priv, time.Now().Add(-time.Minute))
+	test.AssertNilError(t, err)
+	serialized, err = envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	_, _, err = ConsumeEnvelope(serialized, rec.Domain())
+	if !errors.Is(err, ErrEnvelopeExpired) {
+		t.Errorf("expected ErrEnvelopeExpired, got %v", err)
+	}
+	_, err = ConsumeTypedEnvelope(serialized, &simpleRecord{})
+	if !errors.Is(err, ErrEnvelopeExpired) {
+		t.Errorf("expected ErrEnvelopeExpired, got %v", err)
+	}
+
+	// no expiry
+	envelope, err = Seal(rec, priv)
+	test.AssertNilError(t, err)
+	if !envelope.Expiry.IsZero() {
+		t.Error("expected no expiry")
+	}
+	serialized, err = envelope.Marshal()
+	test.AssertNilError(t, err)
+	_, _, err = ConsumeEnvelope(serialized, rec.Domain())
+	test.AssertNilError(t, err)
+}
+
+// Envelopes signed before expiries were introduced must still validate.
+func TestEnvelopeWithoutExpiryIsBackwardCompatible(t *testing.T) {
+	var (
+		rec            = &simpleRecord{message: "hello world!"}
+		priv, pub, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+	RegisterType(&simpleRecord{})
+
+	var unsigned []byte
+	for _, f := range [][]byte{[]byte(rec.Domain()), rec.Codec(), []byte(rec.message)} {
+		unsigned = append(unsigned, varint.ToUvarint(uint64(len(f)))...)
+		unsigned = append(unsigned, f...)
+	}
+	sig, err := priv.Sign(unsigned)
+	test.AssertNilError(t, err)
+	pubProto, err := crypto.PublicKeyToProto(pub)
+	test.AssertNilError(t, err)
+
+	serialized, err := proto.Marshal(&pb.Envelope{
+		PublicKey:   pubProto,
+		PayloadType: rec.Codec(),
+		Payload:     []byte(rec.message),
+		Signature:   sig,
+	})
+	test.AssertNilError(t, err)
+
+	_, _, err = ConsumeEnvelope(serialized, rec.Domain())
+	test.AssertNilError(t, err)
+}
+
+func TestRegistryConsume(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!", testCodec: []byte("/libp2p/testdata/registry")}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	serialized, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	registry := NewRegistry()
+	_, _, err = registry.Consume(serialized, rec.Domain())
+	if !errors.Is(err, ErrPayloadTypeNotRegistered) {
+		t.Fatalf("expected ErrPayloadTypeNotRegistered, got %v", err)
+	}
+
+	registry.Register(rec.Codec(), rec)
+	deserialized, rec2, err := registry.Consume(serialized, rec.Domain())
+	test.AssertNilError(t, err)
+	if rec2.(*simpleRecord).message != rec.message {
+		t.Error("unexpected record contents")
+	}
+	rec3, err := deserialized.Record()
+	test.AssertNilError(t, err)
+	if rec3 != rec2 {
+		t.Error("expected the envelope to cache the record")
+	}
+
+	// The registration must not leak into the default registry.
+	_, _, err = ConsumeEnvelope(serialized, rec.Domain())
+	if !errors.Is(err, ErrPayloadTypeNotRegistered) {
+		t.Fatalf("expected ErrPayloadTypeNotRegistered, got %v", err)
+	}
+}
+
+func TestConsumeEnvelopeBatch(t *testing.T) {
+	RegisterType(&simpleRecord{})
+	domain := (&simpleRecord{}).Domain()
+
+	var serialized [][]byte
+	for i := 0; i < 5; i++ {
+		priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+		test.AssertNilError(t, err)
+		envelope, err := Seal(&simpleRecord{message: fmt.Sprintf("message %d", i)}, priv)
+		test.AssertNilError(t, err)
+		data, err := envelope.Marshal()
+		test.AssertNilError(t, err)
+		serialized = append(serialized, data)
+	}
+
+	envelopes, records, errs := ConsumeEnvelopeBatch(serialized, domain)
+	for i := range serialized {
+		test.AssertNilError(t, errs[i])
+		if envelopes[i] == nil {
+			t.Fatalf("envelope %d: expected an envelope", i)
+		}
+		if msg := records[i].(*simpleRecord).message; msg != fmt.Sprintf("message %d", i) {
+			t.Fatalf("envelope %d: unexpected record %q", i, msg)
+		}
+	}
+
+	// One garbage envelope, one with a bad signature, one expired.
+	serialized[1] = []byte("garbage")
+	envelope, err := UnmarshalEnvelope(serialized[2])
+	test.AssertNilError(t, err)
+	serialized[2] = alterMessageAndMarshal(t, envelope, func(msg *pb.Envelope) {
+		msg.Payload = []byte("totally legit, trust me")
+	})
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	envelope, err = SealWithExpiry(&simpleRecord{message: "expired"}, priv, time.Now().Add(-time.Minute))
+	test.AssertNilError(t, err)
+	serialized[3], err = envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	envelopes, records, errs = ConsumeEnvelopeBatch(serialized, domain)
+	if envelopes[1] != nil || errs[1] == nil {
+		t.Error("expected unmarshalling the garbage envelope to fail")
+	}
+	if envelopes[2] == nil || !errors.Is(errs[2], ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", errs[2])
+	}
+	if envelopes[3] == nil || !errors.Is(errs[3], ErrEnvelopeExpired) {
+		t.Errorf("expected ErrEnvelopeExpired, got %v", errs[3])
+	}
+	for _, i := range []int{0, 4} {
+		test.AssertNilError(t, errs[i])
+		if records[i] == nil {
+			t.Errorf("envelope %d: expected a record", i)
+		}
+	}
+	for _, i := range []int{1, 2, 3} {
+		if records[i] != nil {
+			t.Errorf("envelope %d: expected no record", i)
+		}
+	}
+}
+
+func TestEnvelopeCompression(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: strings.Repeat("/ip4/127.0.0.1/tcp/4001 ", 100)}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+	RegisterType(&simpleRecord{})
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+
+	plain, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	compressed, err := envelope.MarshalCompressed()
+	test.AssertNilError(t, err)
+	if len(compressed) >= len(plain) {
+		t.Errorf("expected compression to shrink the envelope: %d >= %d bytes", len(compressed), len(plain))
+	}
+
+	for _, data := range [][]byte{plain, compressed} {
+		deserialized, rec2, err := ConsumeEnvelope(data, rec.Domain())
+		test.AssertNilError(t, err)
+		if !envelope.Equal(deserialized) {
+			t.Error("round-trip serde results in unequal envelope structures")
+		}
+		if rec2.(*simpleRecord).message != rec.message {
+			t.Error("unexpected record contents")
+		}
+	}
+
+	// Garbage compressed payloads are rejected.
+	garbage := alterMessageAndMarshal(t, envelope, func(msg *pb.Envelope) {
+		msg.Compression = pb.CompressionType_GZIP
+	})
+	_, _, err = ConsumeEnvelope(garbage, rec.Domain())
+	test.ExpectError(t, err, "should not be able to open envelope with an invalid compressed payload")
+}
+
+func TestEnvelopesEqualBytes(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!"}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	plain, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	compressed, err := envelope.MarshalCompressed()
+	test.AssertNilError(t, err)
+
+	if !EnvelopesEqualBytes(plain, plain) {
+		t.Error("expected an envelope to equal itself")
+	}
+	if !EnvelopesEqualBytes(plain, compressed) {
+		t.Error("expected compression not to affect equality")
+	}
+
+	other, err := Seal(&simpleRecord{message: "goodbye world!"}, priv)
+	test.AssertNilError(t, err)
+	otherBytes, err := other.Marshal()
+	test.AssertNilError(t, err)
+	if EnvelopesEqualBytes(plain, otherBytes) {
+		t.Error("expected different envelopes not to be equal")
+	}
+
+	altered := alterMessageAndMarshal(t, envelope, func(msg *pb.Envelope) {
+		msg.Signature = append([]byte(nil), msg.Signature...)
+		msg.Signature[0] ^= 0xff
+	})
+	if EnvelopesEqualBytes(plain, altered) {
+		t.Error("expected envelopes with different signatures not to be equal")
+	}
+	altered = alterMessageAndMarshal(t, envelope, func(msg *pb.Envelope) {
+		msg.PayloadType = []byte("/libp2p/other")
+	})
+	if EnvelopesEqualBytes(plain, altered) {
+		t.Error("expected envelopes with different payload types not to be equal")
+	}
+
+	// Only the signature and the payload type are compared: the payload is
+	// covered by the signature, but isn't checked.
+	altered = alterMessageAndMarshal(t, envelope, func(msg *pb.Envelope) {
+		msg.Payload = []byte("totally legit, trust me")
+	})
+	if !EnvelopesEqualBytes(plain, altered) {
+		t.Error("expected the payload not to be compared")
+	}
+
+	// Field order doesn't matter.
+	var msg pb.Envelope
+	test.AssertNilError(t, msg.Unmarshal(plain))
+	reordered, err := proto.Marshal(&pb.Envelope{Signature: msg.Signature})
+	test.AssertNilError(t, err)
+	head, err := proto.Marshal(&pb.Envelope{PublicKey: msg.PublicKey, PayloadType: msg.PayloadType, Payload: msg.Payload})
+	test.AssertNilError(t, err)
+	reordered = append(reordered, head...)
+	if !EnvelopesEqualBytes(plain, reordered) {
+		t.Error("expected field ordering not to affect equality")
+	}
+
+	if EnvelopesEqualBytes([]byte("garbage"), []byte("garbage")) {
+		t.Error("expected malformed envelopes never to be equal")
+	}
+}
+
+func TestEnvelopeJSON(t *testing.T) {
+	var (
+		rec            = &simpleRecord{message: "hello world!"}
+		priv, pub, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+
+	out, err := json.Marshal(envelope)
+	test.AssertNilError(t, err)
+
+	var decoded struct {
+		PeerID      string
+		PayloadType string
+		RawPayload  []byte
+		Expiry      *time.Time
+		Signature   []byte
+	}
+	test.AssertNilError(t, json.Unmarshal(out, &decoded))
+
+	id, err := peer.IDFromPublicKey(pub)
+	test.AssertNilError(t, err)
+	if decoded.PeerID != id.Pretty() {
+		t.Errorf("expected peer ID %s, got %s", id.Pretty(), decoded.PeerID)
+	}
+	if decoded.PayloadType != "/libp2p/testdata" {
+		t.Errorf("unexpected payload type %q", decoded.PayloadType)
+	}
+	if string(decoded.RawPayload) != "hello world!" {
+		t.Errorf("unexpected payload %q", decoded.RawPayload)
+	}
+	if decoded.Expiry != nil {
+		t.Errorf("expected no expiry, got %s", decoded.Expiry)
+	}
+	if len(decoded.Signature) == 0 {
+		t.Error("expected the signature")
+	}
+	if !strings.Contains(string(out), `"RawPayload":"aGVsbG8gd29ybGQh"`) {
+		t.Errorf("expected the payload to be base64-encoded, got %s", out)
+	}
+
+	// RSA keys aren't inlined in peer IDs.
+	priv, pub, err = test.RandTestKeyPair(crypto.RSA, 2048)
+	test.AssertNilError(t, err)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	envelope, err = SealWithExpiry(rec, priv, expiry)
+	test.AssertNilError(t, err)
+	out, err = json.Marshal(envelope)
+	test.AssertNilError(t, err)
+	test.AssertNilError(t, json.Unmarshal(out, &decoded))
+	id, err = peer.IDFromPublicKey(pub)
+	test.AssertNilError(t, err)
+	if decoded.PeerID != id.Pretty() {
+		t.Errorf("expected peer ID %s, got %s", id.Pretty(), decoded.PeerID)
+	}
+	if decoded.Expiry == nil || !decoded.Expiry.Equal(expiry) {
+		t.Errorf("expected expiry %s, got %v", expiry, decoded.Expiry)
+	}
+}
+
+func TestEnvelopeJSONWithoutInlining(t *testing.T) {
+	peer.AdvancedEnableInlining = false
+	defer func() { peer.AdvancedEnableInlining = true }()
+
+	priv, pub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	envelope, err := Seal(&simpleRecord{message: "hello world!"}, priv)
+	test.AssertNilError(t, err)
+	out, err := json.Marshal(envelope)
+	test.AssertNilError(t, err)
+
+	var decoded struct{ PeerID string }
+	test.AssertNilError(t, json.Unmarshal(out, &decoded))
+	id, err := peer.IDFromPublicKey(pub)
+	test.AssertNilError(t, err)
+	if _, err := id.ExtractPublicKey(); err != peer.ErrNoPublicKey {
+		t.Fatal("expected the key not to be inlined in the peer ID")
+	}
+	if decoded.PeerID != id.Pretty() {
+		t.Errorf("expected peer ID %s, got %s", id.Pretty(), decoded.PeerID)
+	}
+}
+
+func TestEnvelopeJSONWithoutPublicKey(t *testing.T) {
+	out, err := json.Marshal(&Envelope{PayloadType: []byte("/libp2p/testdata")})
+	test.AssertNilError(t, err)
+
+	var decoded struct {
+		PeerID      string
+		PayloadType string
+	}
+	test.AssertNilError(t, json.Unmarshal(out, &decoded))
+	if decoded.PeerID != "" {
+		t.Errorf("expected no peer ID, got %s", decoded.PeerID)
+	}
+	if decoded.PayloadType != "/libp2p/testdata" {
+		t.Errorf("unexpected payload type %q", decoded.PayloadType)
+	}
+}
+
+func TestConsumeEnvelopeErrors(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!", testCodec: []byte("/libp2p/unregistered")}
+		priv, _, err = test.RandTestKeyPair(crypto.RSA, 2048)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	serialized, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	// No Record type is registered for the payload type.
+	_, _, err = ConsumeEnvelope(serialized, rec.Domain())
+	if !errors.Is(err, ErrPayloadTypeNotRegistered) {
+		t.Errorf("expected ErrPayloadTypeNotRegistered, got %v", err)
+	}
+	if errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected the signature to be valid, got %v", err)
+	}
+
+	// The domain mismatch can't be detected without a Record type
+	// registered for the payload type.
+	_, _, err = ConsumeEnvelope(serialized, "wrong-domain")
+	if !errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("expected only ErrInvalidSignature, got %v", err)
+	}
+	_, err = ConsumeTypedEnvelope(serialized, &simpleRecord{testDomain: stringPtr("wrong-domain")})
+	if !errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("expected only ErrInvalidSignature, got %v", err)
+	}
+
+	// It is detected when the payload type's Record type is registered.
+	registry := NewRegistry()
+	registry.Register(rec.Codec(), &simpleRecord{})
+	_, _, err = registry.Consume(serialized, "wrong-domain")
+	if !errors.Is(err, ErrDomainMismatch) || !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrDomainMismatch, got %v", err)
+	}
+	_, _, errs := registry.ConsumeBatch([][]byte{serialized}, "wrong-domain")
+	if !errors.Is(errs[0], ErrDomainMismatch) {
+		t.Errorf("expected ErrDomainMismatch, got %v", errs[0])
+	}
+
+	// RSA keys fail to verify invalid signatures with an error, which must
+	// still be reported as an invalid signature.
+	msg := &pb.Envelope{}
+	test.AssertNilError(t, proto.Unmarshal(serialized, msg))
+	msg.Signature[0] ^= 1
+	tampered, err := proto.Marshal(msg)
+	test.AssertNilError(t, err)
+	_, err = ConsumeTypedEnvelope(tampered, &simpleRecord{})
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	_, _, err = registry.Consume(tampered, "wrong-domain")
+	if !errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("expected a tampered signature not to match ErrDomainMismatch, got %v", err)
+	}
+}
+
+func stringPtr(s string) *string { return &s }
+
+func TestMakeEnvelopeWithSigner(t *testing.T) {
+	var (
+		rec            = &simpleRecord{message: "hello world!"}
+		priv, pub, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	// An HSM-style signing oracle, which doesn't expose the key.
+	var signed int
+	sign := func(msg []byte) ([]byte, error) {
+		signed++
+		return priv.Sign(msg)
+	}
+
+	payload, err := rec.MarshalRecord()
+	test.AssertNilError(t, err)
+	envelope, err := MakeEnvelopeWithSigner(pub, sign, rec.Domain(), rec.Codec(), payload)
+	test.AssertNilError(t, err)
+	if signed != 1 {
+		t.Fatalf("expected the signer to be called once, got %d", signed)
+	}
+
+	// Ed25519 signatures are deterministic, so both envelopes are identical.
+	expected, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	expectedBytes, err := expected.Marshal()
+	test.AssertNilError(t, err)
+	envelopeBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	if !bytes.Equal(envelopeBytes, expectedBytes) {
+		t.Fatal("expected the envelope to be identical to the one sealed with the key")
+	}
+
+	rec2 := &simpleRecord{}
+	_, err = ConsumeTypedEnvelope(envelopeBytes, rec2)
+	test.AssertNilError(t, err)
+	if rec2.message != rec.message {
+		t.Errorf("unexpected message %q", rec2.message)
+	}
+
+	failing := func([]byte) ([]byte, error) { return nil, errors.New("hsm unavailable") }
+	_, err = MakeEnvelopeWithSigner(pub, failing, rec.Domain(), rec.Codec(), payload)
+	test.ExpectError(t, err, "making an envelope should fail if signing fails")
+	_, err = MakeEnvelopeWithSigner(pub, sign, "", rec.Codec(), payload)
+	test.ExpectError(t, err, "making an envelope with an empty domain should fail")
+}
@@ -23,6 +23,32 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// CompressionType identifies how an envelope payload is compressed.
+type CompressionType int32
+
+const (
+	CompressionType_NONE CompressionType = 0
+	CompressionType_GZIP CompressionType = 1
+)
+
+var CompressionType_name = map[int32]string{
+	0: "NONE",
+	1: "GZIP",
+}
+
+var CompressionType_value = map[string]int32{
+	"NONE": 0,
+	"GZIP": 1,
+}
+
+func (x CompressionType) String() string {
+	return proto.EnumName(CompressionType_name, int32(x))
+}
+
+func (CompressionType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ee266e8c558e9dc5, []int{0}
+}
+
 // Envelope encloses a signed payload produced by a peer, along with the public
 // key of the keypair it was signed with so that it can be statelessly validated
 // by the receiver.
@@ -43,6 +69,13 @@ type Envelope struct {
 	// the enclosed public key, over the payload, prefixing a domain string for
 	// additional security.
 	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// expiry is the time after which the envelope must no longer be
+	// considered valid, in seconds since the unix epoch. A value of 0 means
+	// the envelope never expires. When set, it is covered by the signature.
+	Expiry uint64 `protobuf:"varint,6,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	// compression is the compression algorithm applied to the payload on the
+	// wire. The signature always covers the uncompressed payload.
+	Compression CompressionType `protobuf:"varint,7,opt,name=compression,proto3,enum=record.pb.CompressionType" json:"compression,omitempty"`
 }
 
 func (m *Envelope) Reset()         { *m = Envelope{} }
@@ -106,27 +139,47 @@ func (m *Envelope) GetSignature() []byte {
 	return nil
 }
 
+func (m *Envelope) GetExpiry() uint64 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
+func (m *Envelope) GetCompression() CompressionType {
+	if m != nil {
+		return m.Compression
+	}
+	return CompressionType_NONE
+}
+
 func init() {
+	proto.RegisterEnum("record.pb.CompressionType", CompressionType_name, CompressionType_value)
 	proto.RegisterType((*Envelope)(nil), "record.pb.Envelope")
 }
 
 func init() { proto.RegisterFile("envelope.proto", fileDescriptor_ee266e8c558e9dc5) }
 
 var fileDescriptor_ee266e8c558e9dc5 = []byte{
-	// 205 bytes of a gzipped FileDescriptorProto
+	// 280 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4b, 0xcd, 0x2b, 0x4b,
 	0xcd, 0xc9, 0x2f, 0x48, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x2c, 0x4a, 0x4d, 0xce,
 	0x2f, 0x4a, 0xd1, 0x2b, 0x48, 0x92, 0x12, 0x4b, 0x2e, 0xaa, 0x2c, 0x28, 0xc9, 0xd7, 0x2f, 0x48,
-	0xd2, 0x87, 0xb0, 0x20, 0x4a, 0x94, 0x66, 0x31, 0x72, 0x71, 0xb8, 0x42, 0x75, 0x09, 0x19, 0x73,
+	0xd2, 0x87, 0xb0, 0x20, 0x4a, 0x94, 0x3e, 0x30, 0x72, 0x71, 0xb8, 0x42, 0x75, 0x09, 0x19, 0x73,
 	0x71, 0x15, 0x94, 0x26, 0xe5, 0x64, 0x26, 0xc7, 0x67, 0xa7, 0x56, 0x4a, 0x30, 0x2a, 0x30, 0x6a,
 	0x70, 0x1b, 0x89, 0xe8, 0xc1, 0xd4, 0x27, 0xe9, 0x05, 0x80, 0x25, 0xbd, 0x53, 0x2b, 0x83, 0x38,
 	0x0b, 0x60, 0x4c, 0x21, 0x45, 0x2e, 0x9e, 0x82, 0xc4, 0xca, 0x9c, 0xfc, 0xc4, 0x94, 0xf8, 0x92,
 	0xca, 0x82, 0x54, 0x09, 0x26, 0x05, 0x46, 0x0d, 0x9e, 0x20, 0x6e, 0xa8, 0x58, 0x48, 0x65, 0x41,
 	0xaa, 0x90, 0x04, 0x17, 0x3b, 0x94, 0x2b, 0xc1, 0x0c, 0x96, 0x85, 0x71, 0x85, 0x64, 0xb8, 0x38,
-	0x8b, 0x33, 0xd3, 0xf3, 0x12, 0x4b, 0x4a, 0x8b, 0x52, 0x25, 0x58, 0xc1, 0x72, 0x08, 0x01, 0x27,
-	0x89, 0x13, 0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0x71, 0xc2, 0x63,
-	0x39, 0x86, 0x0b, 0x8f, 0xe5, 0x18, 0x6e, 0x3c, 0x96, 0x63, 0x48, 0x62, 0x03, 0xbb, 0xde, 0x18,
-	0x10, 0x00, 0x00, 0xff, 0xff, 0xaa, 0x0b, 0xd9, 0x6d, 0xf2, 0x00, 0x00, 0x00,
+	0x8b, 0x33, 0xd3, 0xf3, 0x12, 0x4b, 0x4a, 0x8b, 0x52, 0x25, 0x58, 0xc1, 0x72, 0x08, 0x01, 0x21,
+	0x31, 0x2e, 0xb6, 0xd4, 0x8a, 0x82, 0xcc, 0xa2, 0x4a, 0x09, 0x36, 0x05, 0x46, 0x0d, 0x96, 0x20,
+	0x28, 0x4f, 0xc8, 0x86, 0x8b, 0x3b, 0x39, 0x3f, 0xb7, 0xa0, 0x28, 0xb5, 0xb8, 0x38, 0x33, 0x3f,
+	0x4f, 0x82, 0x5d, 0x81, 0x51, 0x83, 0xcf, 0x48, 0x4a, 0x0f, 0xee, 0x5b, 0x3d, 0x67, 0x84, 0x2c,
+	0xc8, 0x01, 0x41, 0xc8, 0xca, 0xb5, 0x54, 0xb9, 0xf8, 0xd1, 0xe4, 0x85, 0x38, 0xb8, 0x58, 0xfc,
+	0xfc, 0xfd, 0x5c, 0x05, 0x18, 0x40, 0x2c, 0xf7, 0x28, 0xcf, 0x00, 0x01, 0x46, 0x27, 0x89, 0x13,
+	0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0x71, 0xc2, 0x63, 0x39, 0x86,
+	0x0b, 0x8f, 0xe5, 0x18, 0x6e, 0x3c, 0x96, 0x63, 0x48, 0x62, 0x03, 0x07, 0x9d, 0x31, 0x60, 0x00,
+	0x91, 0x61, 0xc0, 0x6d, 0x6f, 0x01, 0x00, 0x00,
 }
 
 func (m *Envelope) Marshal() (dAtA []byte, err error) {
@@ -149,6 +202,16 @@ func (m *Envelope) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Compression != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.Compression))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Expiry != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.Expiry))
+		i--
+		dAtA[i] = 0x30
+	}
 	if len(m.Signature) > 0 {
 		i -= len(m.Signature)
 		copy(dAtA[i:], m.Signature)
@@ -218,6 +281,12 @@ func (m *Envelope) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovEnvelope(uint64(l))
 	}
+	if m.Expiry != 0 {
+		n += 1 + sovEnvelope(uint64(m.Expiry))
+	}
+	if m.Compression != 0 {
+		n += 1 + sovEnvelope(uint64(m.Compression))
+	}
 	return n
 }
 
@@ -394,6 +463,44 @@ func (m *Envelope) Unmarshal(dAtA []byte) error {
 				m.Signature = []byte{}
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expiry", wireType)
+			}
+			m.Expiry = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Expiry |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			m.Compression = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Compression |= CompressionType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEnvelope(dAtA[iNdEx:])
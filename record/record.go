@@ -3,6 +3,8 @@ package record
 import (
 	"errors"
 	"reflect"
+	"sync"
+	"time"
 )
 
 var (
@@ -10,7 +12,9 @@ var (
 	// PayloadType does not match any registered Record types.
 	ErrPayloadTypeNotRegistered = errors.New("payload type is not registered")
 
-	payloadTypeRegistry = make(map[string]reflect.Type)
+	// DefaultRegistry is the Registry used by the package-level RegisterType and
+	// ConsumeEnvelope functions, and by Envelope.Record.
+	DefaultRegistry = NewRegistry()
 )
 
 // Record represents a data type that can be used as the payload of an Envelope.
@@ -48,10 +52,30 @@ type Record interface {
 	UnmarshalRecord([]byte) error
 }
 
+// TTLRecord is an optional interface for Records that know for how long they
+// should be considered valid, e.g. so that a peerstore can derive the expiry
+// of a record from the record itself.
+type TTLRecord interface {
+	Record
+
+	// TTL returns the duration for which the Record should be considered valid.
+	TTL() time.Duration
+}
+
+// RecordTTL returns the TTL of the given Record, and true, if it implements
+// TTLRecord. Otherwise, it returns false.
+func RecordTTL(r Record) (time.Duration, bool) {
+	tr, ok := r.(TTLRecord)
+	if !ok {
+		return 0, false
+	}
+	return tr.TTL(), true
+}
+
 // RegisterType associates a binary payload type identifier with a concrete
-// Record type. This is used to automatically unmarshal Record payloads from Envelopes
-// when using ConsumeEnvelope, and to automatically marshal Records and determine the
-// correct PayloadType when calling Seal.
+// Record type, in the shared DefaultRegistry. This is used to automatically unmarshal
+// Record payloads from Envelopes when using ConsumeEnvelope, and to automatically marshal
+// Records and determine the correct PayloadType when calling Seal.
 //
 // Callers must provide an instance of the record type to be registered, which must be
 // a pointer type. Registration should be done in the init function of the package
@@ -67,11 +91,51 @@ type Record interface {
 //    type HelloRecord struct { } // etc..
 //
 func RegisterType(prototype Record) {
-	payloadTypeRegistry[string(prototype.Codec())] = getValueType(prototype)
+	DefaultRegistry.Register(prototype.Codec(), prototype)
+}
+
+// Registry maps Envelope payload types to the Record types used to unmarshal
+// them. Most users should rely on the shared DefaultRegistry, through the
+// package-level RegisterType and ConsumeEnvelope functions. Separate
+// registries are useful to isolate subsystems or tests from each other.
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	lk    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]reflect.Type)}
+}
+
+// Register associates the given payload type with the concrete type of the
+// given Record, in this Registry. See RegisterType for details.
+//
+// The payload type is usually prototype.Codec(), which Seal uses as the
+// PayloadType of the Envelopes it creates.
+func (r *Registry) Register(payloadType []byte, prototype Record) {
+	r.lk.Lock()
+	r.types[string(payloadType)] = getValueType(prototype)
+	r.lk.Unlock()
+}
+
+// Consume is like ConsumeEnvelope, but looks up the Record type of the
+// Envelope's payload in this Registry. The returned Envelope's Record method
+// uses this Registry as well.
+func (r *Registry) Consume(data []byte, domain string) (envelope *Envelope, rec Record, err error) {
+	return consumeEnvelope(r, data, domain)
+}
+
+// ConsumeBatch is like ConsumeEnvelopeBatch, but looks up Record types in this
+// Registry.
+func (r *Registry) ConsumeBatch(serialized [][]byte, domain string) (envelopes []*Envelope, records []Record, errs []error) {
+	return consumeEnvelopeBatch(r, serialized, domain)
 }
 
-func unmarshalRecordPayload(payloadType []byte, payloadBytes []byte) (Record, error) {
-	rec, err := blankRecordForPayloadType(payloadType)
+func (r *Registry) unmarshalRecordPayload(payloadType []byte, payloadBytes []byte) (Record, error) {
+	rec, err := r.blankRecordForPayloadType(payloadType)
 	if err != nil {
 		return nil, err
 	}
@@ -82,8 +146,10 @@ func unmarshalRecordPayload(payloadType []byte, payloadBytes []byte) (Record, er
 	return rec, nil
 }
 
-func blankRecordForPayloadType(payloadType []byte) (Record, error) {
-	valueType, ok := payloadTypeRegistry[string(payloadType)]
+func (r *Registry) blankRecordForPayloadType(payloadType []byte) (Record, error) {
+	r.lk.RLock()
+	valueType, ok := r.types[string(payloadType)]
+	r.lk.RUnlock()
 	if !ok {
 		return nil, ErrPayloadTypeNotRegistered
 	}
@@ -1,6 +1,9 @@
 package record
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 var testPayloadType = []byte("/libp2p/test/record/payload-type")
 
@@ -27,7 +30,7 @@ func (p *testPayload) UnmarshalRecord(bytes []byte) error {
 
 func TestUnmarshalPayload(t *testing.T) {
 	t.Run("fails if payload type is unregistered", func(t *testing.T) {
-		_, err := unmarshalRecordPayload([]byte("unknown type"), []byte{})
+		_, err := DefaultRegistry.unmarshalRecordPayload([]byte("unknown type"), []byte{})
 		if err != ErrPayloadTypeNotRegistered {
 			t.Error("Expected error when unmarshalling payload with unregistered payload type")
 		}
@@ -36,7 +39,7 @@ func TestUnmarshalPayload(t *testing.T) {
 	t.Run("calls UnmarshalRecord on concrete Record type", func(t *testing.T) {
 		RegisterType(&testPayload{})
 
-		payload, err := unmarshalRecordPayload(testPayloadType, []byte{})
+		payload, err := DefaultRegistry.unmarshalRecordPayload(testPayloadType, []byte{})
 		if err != nil {
 			t.Errorf("unexpected error unmarshalling registered payload type: %v", err)
 		}
@@ -49,3 +52,44 @@ func TestUnmarshalPayload(t *testing.T) {
 		}
 	})
 }
+
+func TestRegistryIsolation(t *testing.T) {
+	r := NewRegistry()
+	other := NewRegistry()
+	r.Register(testPayloadType, &testPayload{})
+
+	if _, err := r.unmarshalRecordPayload(testPayloadType, []byte{}); err != nil {
+		t.Errorf("unexpected error unmarshalling registered payload type: %v", err)
+	}
+	if _, err := other.unmarshalRecordPayload(testPayloadType, []byte{}); err != ErrPayloadTypeNotRegistered {
+		t.Error("expected registrations not to leak between registries")
+	}
+
+	// The payload type doesn't have to be the Record's Codec.
+	other.Register([]byte("/libp2p/testing/alias"), &testPayload{})
+	rec, err := other.unmarshalRecordPayload([]byte("/libp2p/testing/alias"), []byte{})
+	if err != nil {
+		t.Fatalf("unexpected error unmarshalling registered payload type: %v", err)
+	}
+	if _, ok := rec.(*testPayload); !ok {
+		t.Errorf("expected a *testPayload, got %T", rec)
+	}
+}
+
+type ttlPayload struct {
+	testPayload
+}
+
+func (p *ttlPayload) TTL() time.Duration {
+	return time.Minute
+}
+
+func TestRecordTTL(t *testing.T) {
+	if _, ok := RecordTTL(&testPayload{}); ok {
+		t.Error("expected a record without TTL to report false")
+	}
+	ttl, ok := RecordTTL(&ttlPayload{})
+	if !ok || ttl != time.Minute {
+		t.Errorf("expected a TTL of one minute, got %s (%t)", ttl, ok)
+	}
+}
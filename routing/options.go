@@ -7,6 +7,7 @@ type Option func(opts *Options) error
 type Options struct {
 	// Allow expired values.
 	Expired bool
+	// Only use local data, see the Offline option.
 	Offline bool
 	// Other (ValueStore implementation specific) options.
 	Other map[interface{}]interface{}
@@ -44,6 +45,10 @@ var Expired Option = func(opts *Options) error {
 }
 
 // Offline is an option that tells the routing system to operate offline (i.e., rely on cached/local data only).
+//
+// Implementations honoring it must not perform any network I/O. Lookups that
+// can't be answered from local data fail fast with ErrNotFound instead of
+// blocking.
 var Offline Option = func(opts *Options) error {
 	opts.Offline = true
 	return nil
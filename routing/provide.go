@@ -0,0 +1,31 @@
+package routing
+
+import (
+	"context"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// TTLProvider is an optional interface implemented by content routers whose
+// provider records can expire after a caller-chosen duration, e.g. to avoid
+// leaving stale records behind for short-lived content.
+type TTLProvider interface {
+	// ProvideWithTTL is like Provide with announce set to true, but asks
+	// for the provider record to expire after ttl. Backends may clamp ttl
+	// to the range they support.
+	ProvideWithTTL(ctx context.Context, c cid.Cid, ttl time.Duration) error
+}
+
+// ProvideWithTTL announces c with a provider record expiring after ttl, if r
+// implements TTLProvider.
+//
+// Otherwise, or if ttl is 0, it calls Provide and the record expires after
+// the backend's default provider record lifetime (e.g. a day or two for a
+// DHT) unless it is re-provided.
+func ProvideWithTTL(ctx context.Context, r ContentRouting, c cid.Cid, ttl time.Duration) error {
+	if p, ok := r.(TTLProvider); ok && ttl > 0 {
+		return p.ProvideWithTTL(ctx, c, ttl)
+	}
+	return r.Provide(ctx, c, true)
+}
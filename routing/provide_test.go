@@ -0,0 +1,54 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+type ttlRouter struct {
+	stubRouter
+	ttl time.Duration
+}
+
+func (r *ttlRouter) ProvideWithTTL(ctx context.Context, c cid.Cid, ttl time.Duration) error {
+	r.record("ProvideWithTTL")
+	r.ttl = ttl
+	return r.err
+}
+
+func TestProvideWithTTL(t *testing.T) {
+	ctx := context.Background()
+
+	tr := &ttlRouter{}
+	if err := ProvideWithTTL(ctx, tr, cid.Cid{}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if tr.ttl != time.Minute || len(tr.calls) != 1 || tr.calls[0] != "ProvideWithTTL" {
+		t.Fatalf("expected ProvideWithTTL to be called, got %v (ttl %s)", tr.calls, tr.ttl)
+	}
+
+	// Without a TTL, or without TTLProvider, fall back to Provide.
+	if err := ProvideWithTTL(ctx, tr, cid.Cid{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	sr := &stubRouter{}
+	if err := ProvideWithTTL(ctx, sr, cid.Cid{}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if tr.calls[1] != "Provide" || len(sr.calls) != 1 || sr.calls[0] != "Provide" {
+		t.Fatalf("expected Provide to be called, got %v and %v", tr.calls, sr.calls)
+	}
+
+	// Tiered routers forward the TTL to the routers supporting it.
+	tr = &ttlRouter{}
+	sr = &stubRouter{}
+	if err := ProvideWithTTL(ctx, Tiered(tr, sr), cid.Cid{}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if tr.ttl != time.Minute || sr.calls[0] != "Provide" {
+		t.Fatalf("unexpected calls: %v and %v", tr.calls, sr.calls)
+	}
+}
@@ -27,6 +27,9 @@ type ContentRouting interface {
 	// Provide adds the given cid to the content routing system. If 'true' is
 	// passed, it also announces it, otherwise it is just kept in the local
 	// accounting of which objects are being provided.
+	//
+	// Provider records expire after a backend-specific default lifetime.
+	// See ProvideWithTTL to choose it.
 	Provide(context.Context, cid.Cid, bool) error
 
 	// Search for peers who are able to provide a given key
@@ -74,12 +77,29 @@ type Routing interface {
 	ContentRouting
 	PeerRouting
 	ValueStore
+	Bootstrapper
+
+	// TODO expose io.Closer or plain-old Close error
+}
 
+// Bootstrapper is implemented by routers that need to be bootstrapped before
+// use. All Routings implement it, but narrower routers (e.g. a PeerRouting)
+// may implement it as well.
+type Bootstrapper interface {
 	// Bootstrap allows callers to hint to the routing system to get into a
 	// Boostrapped state and remain there. It is not a synchronous call.
 	Bootstrap(context.Context) error
+}
 
-	// TODO expose io.Closer or plain-old Close error
+// BootstrapIfSupported bootstraps r if it implements Bootstrapper. r is
+// typically a Routing, ContentRouting, PeerRouting or ValueStore. Routers
+// that don't implement Bootstrapper are treated as already bootstrapped, and
+// nil is returned.
+func BootstrapIfSupported(ctx context.Context, r interface{}) error {
+	if b, ok := r.(Bootstrapper); ok {
+		return b.Bootstrap(ctx)
+	}
+	return nil
 }
 
 // PubKeyFetcher is an interfaces that should be implemented by value stores
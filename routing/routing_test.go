@@ -0,0 +1,24 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBootstrapIfSupported(t *testing.T) {
+	ctx := context.Background()
+	bootErr := errors.New("boom")
+	r := &stubRouter{err: bootErr}
+	if err := BootstrapIfSupported(ctx, r); err != bootErr {
+		t.Fatalf("expected the router's error, got %v", err)
+	}
+	if len(r.calls) != 1 || r.calls[0] != "Bootstrap" {
+		t.Fatalf("expected Bootstrap to be called, got %v", r.calls)
+	}
+
+	// A ValueStore without a Bootstrap method is a no-op.
+	if err := BootstrapIfSupported(ctx, &valueStore{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,75 @@
+package routing
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// SearchResult is a value found by SearchValueStream, along with its
+// provenance.
+type SearchResult struct {
+	// Value is the value found.
+	Value []byte
+	// From is the peer the value was received from. It is empty when the
+	// provenance is unknown, or when the value was found locally.
+	From peer.ID
+	// Quorum is the number of responses that agreed on this value so far,
+	// or 0 if unknown.
+	Quorum int
+}
+
+// ValueStreamer is an optional interface implemented by value stores that can
+// report the provenance of the values found while searching.
+type ValueStreamer interface {
+	// SearchValueStream is like ValueStore.SearchValue, but returns the
+	// values along with their provenance.
+	SearchValueStream(context.Context, string, ...Option) (<-chan SearchResult, error)
+}
+
+// SearchValueStream searches for better and better values corresponding to
+// the given key, like ValueStore.SearchValue, and returns them along with
+// their provenance.
+//
+// If the ValueStore is a ValueStreamer, this calls SearchValueStream.
+// Otherwise, it calls SearchValue and the results' provenance is unknown.
+func SearchValueStream(ctx context.Context, r ValueStore, key string, opts ...Option) (<-chan SearchResult, error) {
+	if vs, ok := r.(ValueStreamer); ok {
+		return vs.SearchValueStream(ctx, key, opts...)
+	}
+
+	values, err := r.SearchValue(ctx, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan SearchResult)
+	go func() {
+		defer close(out)
+		for v := range values {
+			select {
+			case out <- SearchResult{Value: v}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// SearchResultValues projects a channel of search results to a channel of
+// their values. ValueStreamer implementations can use it to implement
+// SearchValue in terms of SearchValueStream.
+func SearchResultValues(ctx context.Context, results <-chan SearchResult) <-chan []byte {
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		for res := range results {
+			select {
+			case out <- res.Value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
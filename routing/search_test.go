@@ -0,0 +1,100 @@
+package routing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+type valueStore struct {
+	values [][]byte
+}
+
+func (vs *valueStore) PutValue(context.Context, string, []byte, ...Option) error { return nil }
+
+func (vs *valueStore) GetValue(context.Context, string, ...Option) ([]byte, error) {
+	return vs.values[len(vs.values)-1], nil
+}
+
+func (vs *valueStore) SearchValue(ctx context.Context, key string, opts ...Option) (<-chan []byte, error) {
+	ch := make(chan []byte, len(vs.values))
+	for _, v := range vs.values {
+		ch <- v
+	}
+	close(ch)
+	return ch, nil
+}
+
+type valueStreamer struct {
+	valueStore
+	from peer.ID
+}
+
+func (vs *valueStreamer) SearchValueStream(ctx context.Context, key string, opts ...Option) (<-chan SearchResult, error) {
+	ch := make(chan SearchResult, len(vs.values))
+	for i, v := range vs.values {
+		ch <- SearchResult{Value: v, From: vs.from, Quorum: i + 1}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (vs *valueStreamer) SearchValue(ctx context.Context, key string, opts ...Option) (<-chan []byte, error) {
+	results, err := vs.SearchValueStream(ctx, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return SearchResultValues(ctx, results), nil
+}
+
+func TestSearchValueStream(t *testing.T) {
+	ctx := context.Background()
+	values := [][]byte{[]byte("old"), []byte("new")}
+
+	results, err := SearchValueStream(ctx, &valueStore{values: values}, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := 0
+	for res := range results {
+		if string(res.Value) != string(values[i]) || res.From != "" || res.Quorum != 0 {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		i++
+	}
+	if i != len(values) {
+		t.Fatalf("expected %d results, got %d", len(values), i)
+	}
+
+	vs := &valueStreamer{valueStore: valueStore{values: values}, from: "peer"}
+	results, err = SearchValueStream(ctx, vs, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i = 0
+	for res := range results {
+		if string(res.Value) != string(values[i]) || res.From != "peer" || res.Quorum != i+1 {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		i++
+	}
+	if i != len(values) {
+		t.Fatalf("expected %d results, got %d", len(values), i)
+	}
+
+	plain, err := vs.SearchValue(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i = 0
+	for v := range plain {
+		if string(v) != string(values[i]) {
+			t.Fatalf("expected %q, got %q", values[i], v)
+		}
+		i++
+	}
+	if i != len(values) {
+		t.Fatalf("expected %d values, got %d", len(values), i)
+	}
+}
@@ -0,0 +1,237 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// RouterErrors is returned by the routers built by Tiered when the underlying
+// routers failed. It holds the error of each failed router, in order.
+type RouterErrors []error
+
+func (es RouterErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d routers failed: %s", len(es), strings.Join(msgs, "; "))
+}
+
+// Is reports whether all the routers failed with target. This lets callers
+// check for ErrNotFound as they would with a single router.
+func (es RouterErrors) Is(target error) bool {
+	if len(es) == 0 {
+		return false
+	}
+	for _, e := range es {
+		if !errors.Is(e, target) {
+			return false
+		}
+	}
+	return true
+}
+
+type tiered []Routing
+
+// Tiered returns a Routing querying the given routers in order, e.g. a fast
+// local router followed by a slower DHT.
+//
+// FindPeer and GetValue return the result of the first router that succeeds.
+// If they all fail, the returned error is a RouterErrors, which matches
+// ErrNotFound (using errors.Is) if all the routers returned it.
+// FindProvidersAsync queries the routers one after the other, forwarding all
+// their results, deduplicated. SearchValue starts the searches of all the
+// routers, and forwards their results in router order; the values found by a
+// router aren't compared with the values found by the previous ones. It fails
+// with a RouterErrors if all the routers fail to start their search, and
+// ignores the failing routers otherwise.
+//
+// Provide, ProvideWithTTL (see TTLProvider) and PutValue are sent to all the
+// routers concurrently, and fail if any of them fails. Routers returning
+// ErrNotSupported are ignored, unless they all do. Bootstrap bootstraps all
+// the routers.
+func Tiered(routers ...Routing) Routing {
+	return append(tiered(nil), routers...)
+}
+
+func (t tiered) FindPeer(ctx context.Context, p peer.ID) (peer.AddrInfo, error) {
+	var errs RouterErrors
+	for _, r := range t {
+		if ctx.Err() != nil {
+			return peer.AddrInfo{}, ctx.Err()
+		}
+		ai, err := r.FindPeer(ctx, p)
+		if err == nil {
+			return ai, nil
+		}
+		errs = append(errs, err)
+	}
+	return peer.AddrInfo{}, firstSuccessErr(errs)
+}
+
+func (t tiered) GetValue(ctx context.Context, key string, opts ...Option) ([]byte, error) {
+	var errs RouterErrors
+	for _, r := range t {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		v, err := r.GetValue(ctx, key, opts...)
+		if err == nil {
+			return v, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, firstSuccessErr(errs)
+}
+
+func (t tiered) SearchValue(ctx context.Context, key string, opts ...Option) (<-chan []byte, error) {
+	var (
+		errs   RouterErrors
+		values []<-chan []byte
+	)
+	for _, r := range t {
+		ch, err := r.SearchValue(ctx, key, opts...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values = append(values, ch)
+	}
+	if len(values) == 0 && len(errs) > 0 {
+		return nil, errs
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		for _, ch := range values {
+			for v := range ch {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (t tiered) FindProvidersAsync(ctx context.Context, c cid.Cid, count int) <-chan peer.AddrInfo {
+	out := make(chan peer.AddrInfo)
+	go func() {
+		defer close(out)
+		seen := make(map[peer.ID]struct{})
+		for _, r := range t {
+			if !forwardProviders(ctx, r, c, count, seen, out) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// forwardProviders forwards the providers found by r that haven't been seen
+// yet. It returns false when the search is over, either because count
+// providers were found or because ctx is done.
+func forwardProviders(ctx context.Context, r Routing, c cid.Cid, count int, seen map[peer.ID]struct{}, out chan<- peer.AddrInfo) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for ai := range r.FindProvidersAsync(ctx, c, count) {
+		if _, ok := seen[ai.ID]; ok {
+			continue
+		}
+		seen[ai.ID] = struct{}{}
+		select {
+		case out <- ai:
+		case <-ctx.Done():
+			return false
+		}
+		if count > 0 && len(seen) >= count {
+			return false
+		}
+	}
+	return ctx.Err() == nil
+}
+
+func (t tiered) Provide(ctx context.Context, c cid.Cid, announce bool) error {
+	return t.fanOut(func(r Routing) error {
+		return r.Provide(ctx, c, announce)
+	})
+}
+
+func (t tiered) ProvideWithTTL(ctx context.Context, c cid.Cid, ttl time.Duration) error {
+	return t.fanOut(func(r Routing) error {
+		return ProvideWithTTL(ctx, r, c, ttl)
+	})
+}
+
+func (t tiered) PutValue(ctx context.Context, key string, value []byte, opts ...Option) error {
+	return t.fanOut(func(r Routing) error {
+		return r.PutValue(ctx, key, value, opts...)
+	})
+}
+
+func (t tiered) Bootstrap(ctx context.Context) error {
+	var errs RouterErrors
+	for _, r := range t {
+		if err := r.Bootstrap(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// fanOut calls f on all the routers concurrently, and returns their errors,
+// ignoring ErrNotSupported unless all the routers returned it.
+func (t tiered) fanOut(f func(Routing) error) error {
+	results := make([]error, len(t))
+	var wg sync.WaitGroup
+	for i, r := range t {
+		wg.Add(1)
+		go func(i int, r Routing) {
+			defer wg.Done()
+			results[i] = f(r)
+		}(i, r)
+	}
+	wg.Wait()
+
+	var (
+		errs        RouterErrors
+		unsupported int
+	)
+	for _, err := range results {
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrNotSupported):
+			unsupported++
+		default:
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if len(t) > 0 && unsupported == len(t) {
+		return ErrNotSupported
+	}
+	return nil
+}
+
+func firstSuccessErr(errs RouterErrors) error {
+	if len(errs) == 0 {
+		return ErrNotFound
+	}
+	return errs
+}
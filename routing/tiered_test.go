@@ -0,0 +1,187 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	cid "github.com/ipfs/go-cid"
+)
+
+// stubRouter records the calls made to it and returns canned results.
+type stubRouter struct {
+	mu    sync.Mutex
+	calls []string
+
+	peer      peer.AddrInfo
+	value     []byte
+	providers []peer.AddrInfo
+	err       error
+}
+
+func (r *stubRouter) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *stubRouter) Provide(context.Context, cid.Cid, bool) error {
+	r.record("Provide")
+	return r.err
+}
+
+func (r *stubRouter) FindProvidersAsync(ctx context.Context, c cid.Cid, count int) <-chan peer.AddrInfo {
+	r.record("FindProvidersAsync")
+	ch := make(chan peer.AddrInfo, len(r.providers))
+	for _, ai := range r.providers {
+		ch <- ai
+	}
+	close(ch)
+	return ch
+}
+
+func (r *stubRouter) FindPeer(context.Context, peer.ID) (peer.AddrInfo, error) {
+	r.record("FindPeer")
+	return r.peer, r.err
+}
+
+func (r *stubRouter) PutValue(context.Context, string, []byte, ...Option) error {
+	r.record("PutValue")
+	return r.err
+}
+
+func (r *stubRouter) GetValue(context.Context, string, ...Option) ([]byte, error) {
+	r.record("GetValue")
+	return r.value, r.err
+}
+
+func (r *stubRouter) SearchValue(context.Context, string, ...Option) (<-chan []byte, error) {
+	r.record("SearchValue")
+	if r.err != nil {
+		return nil, r.err
+	}
+	ch := make(chan []byte, 1)
+	if r.value != nil {
+		ch <- r.value
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (r *stubRouter) Bootstrap(context.Context) error {
+	r.record("Bootstrap")
+	return r.err
+}
+
+func TestTieredFirstSuccess(t *testing.T) {
+	ctx := context.Background()
+	failing := &stubRouter{err: ErrNotFound}
+	local := &stubRouter{peer: peer.AddrInfo{ID: "local"}, value: []byte("local")}
+	dht := &stubRouter{peer: peer.AddrInfo{ID: "dht"}, value: []byte("dht")}
+	r := Tiered(failing, local, dht)
+
+	ai, err := r.FindPeer(ctx, "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ai.ID != "local" {
+		t.Fatalf("expected the first successful router's result, got %s", ai.ID)
+	}
+	v, err := r.GetValue(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v) != "local" {
+		t.Fatalf("expected the first successful router's result, got %s", v)
+	}
+	if len(failing.calls) != 2 || len(local.calls) != 2 || len(dht.calls) != 0 {
+		t.Fatalf("unexpected calls: %v, %v, %v", failing.calls, local.calls, dht.calls)
+	}
+}
+
+func TestTieredAllFail(t *testing.T) {
+	ctx := context.Background()
+	other := errors.New("boom")
+
+	_, err := Tiered(&stubRouter{err: ErrNotFound}, &stubRouter{err: ErrNotFound}).FindPeer(ctx, "p")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err = Tiered(&stubRouter{err: ErrNotFound}, &stubRouter{err: other}).GetValue(ctx, "key")
+	errs, ok := err.(RouterErrors)
+	if !ok || len(errs) != 2 || errs[0] != ErrNotFound || errs[1] != other {
+		t.Fatalf("expected both errors, got %v", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Fatal("didn't expect ErrNotFound when a router failed otherwise")
+	}
+
+	if _, err := Tiered().FindPeer(ctx, "p"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound without routers, got %v", err)
+	}
+}
+
+func TestTieredFanOut(t *testing.T) {
+	ctx := context.Background()
+	a, b := &stubRouter{}, &stubRouter{err: ErrNotSupported}
+	r := Tiered(a, b)
+	if err := r.PutValue(ctx, "key", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Provide(ctx, cid.Cid{}, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, sr := range []*stubRouter{a, b} {
+		if len(sr.calls) != 2 || sr.calls[0] != "PutValue" || sr.calls[1] != "Provide" {
+			t.Fatalf("expected all routers to be called, got %v", sr.calls)
+		}
+	}
+
+	if err := Tiered(b, b).PutValue(ctx, "key", nil); err != ErrNotSupported {
+		t.Fatalf("expected ErrNotSupported, got %v", err)
+	}
+	other := errors.New("boom")
+	if err := Tiered(a, &stubRouter{err: other}).Provide(ctx, cid.Cid{}, true); !errors.Is(err, other) {
+		t.Fatalf("expected the failing router's error, got %v", err)
+	}
+}
+
+func TestTieredFindProviders(t *testing.T) {
+	ctx := context.Background()
+	local := &stubRouter{providers: []peer.AddrInfo{{ID: "a"}, {ID: "b"}}}
+	dht := &stubRouter{providers: []peer.AddrInfo{{ID: "b"}, {ID: "c"}, {ID: "d"}}}
+
+	var found []peer.ID
+	for ai := range Tiered(local, dht).FindProvidersAsync(ctx, cid.Cid{}, 3) {
+		found = append(found, ai.ID)
+	}
+	if len(found) != 3 || found[0] != "a" || found[1] != "b" || found[2] != "c" {
+		t.Fatalf("expected deduplicated providers in router order, got %v", found)
+	}
+}
+
+func TestTieredSearchValue(t *testing.T) {
+	ctx := context.Background()
+	local := &stubRouter{value: []byte("local")}
+	dht := &stubRouter{value: []byte("dht")}
+	values, err := Tiered(&stubRouter{err: ErrNotSupported}, local, dht).SearchValue(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for v := range values {
+		found = append(found, string(v))
+	}
+	if len(found) != 2 || found[0] != "local" || found[1] != "dht" {
+		t.Fatalf("expected the values in router order, got %v", found)
+	}
+
+	_, err = Tiered(&stubRouter{err: ErrNotSupported}, &stubRouter{err: ErrNotSupported}).SearchValue(ctx, "key")
+	if _, ok := err.(RouterErrors); !ok || !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("expected RouterErrors matching ErrNotSupported, got %v", err)
+	}
+}
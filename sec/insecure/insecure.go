@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/libp2p/go-libp2p-core/sec"
 	"github.com/libp2p/go-msgio"
 
@@ -29,6 +31,9 @@ const ID = "/plaintext/2.0.0"
 type Transport struct {
 	id  peer.ID
 	key ci.PrivKey
+
+	verifierMu sync.RWMutex
+	verifier   sec.PeerVerifier
 }
 
 // New constructs a new insecure transport.
@@ -61,6 +66,31 @@ func (t *Transport) LocalPrivateKey() ci.PrivKey {
 	return t.key
 }
 
+// SetPeerVerifier sets the PeerVerifier consulted once the remote peer's ID
+// and public key have been exchanged. As the insecure transport doesn't
+// authenticate remote peers, it is only consulted when the transport has a
+// private key. It is safe to call concurrently with handshakes, which use the
+// PeerVerifier set when they complete.
+func (t *Transport) SetPeerVerifier(v sec.PeerVerifier) {
+	t.verifierMu.Lock()
+	t.verifier = v
+	t.verifierMu.Unlock()
+}
+
+func (t *Transport) verify(conn *Conn) error {
+	t.verifierMu.RLock()
+	verifier := t.verifier
+	t.verifierMu.RUnlock()
+
+	if verifier == nil || t.key == nil {
+		return nil
+	}
+	if err := verifier.VerifyPeer(conn.remote, conn.remotePubKey); err != nil {
+		return fmt.Errorf("peer %s rejected: %w", conn.remote, err)
+	}
+	return nil
+}
+
 // SecureInbound *pretends to secure* an inbound connection to the given peer.
 // It sends the local peer's ID and public key, and receives the same from the remote peer.
 // No validation is performed as to the authenticity or ownership of the provided public key,
@@ -80,6 +110,10 @@ func (t *Transport) SecureInbound(ctx context.Context, insecure net.Conn) (sec.S
 		return nil, err
 	}
 
+	if err := t.verify(conn); err != nil {
+		return nil, err
+	}
+
 	return conn, nil
 }
 
@@ -108,6 +142,10 @@ func (t *Transport) SecureOutbound(ctx context.Context, insecure net.Conn, p pee
 			p, conn.remote)
 	}
 
+	if err := t.verify(conn); err != nil {
+		return nil, err
+	}
+
 	return conn, nil
 }
 
@@ -234,5 +272,12 @@ func (ic *Conn) LocalPrivateKey() ci.PrivKey {
 	return ic.localPrivKey
 }
 
+// ConnProtocol returns the protocol ID of the insecure transport.
+func (ic *Conn) ConnProtocol() protocol.ID {
+	return ID
+}
+
 var _ sec.SecureTransport = (*Transport)(nil)
 var _ sec.SecureConn = (*Conn)(nil)
+var _ sec.ProtocolConn = (*Conn)(nil)
+var _ sec.VerifyingTransport = (*Transport)(nil)
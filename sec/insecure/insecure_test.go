@@ -3,6 +3,7 @@ package insecure
 import (
 	"bytes"
 	"context"
+	"errors"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/sec"
 	"io"
@@ -125,6 +126,16 @@ func testKeys(t *testing.T, clientTpt, serverTpt *Transport, clientConn, serverC
 	}
 }
 
+// Check the negotiated protocol
+func testConnProtocol(t *testing.T, clientConn, serverConn sec.SecureConn) {
+	if p := sec.ConnProtocol(clientConn); p != ID {
+		t.Errorf("Client protocol mismatch: %s != %s", p, ID)
+	}
+	if p := sec.ConnProtocol(serverConn); p != ID {
+		t.Errorf("Server protocol mismatch: %s != %s", p, ID)
+	}
+}
+
 // Check sending and receiving messages
 func testReadWrite(t *testing.T, clientConn, serverConn sec.SecureConn) {
 	before := []byte("hello world")
@@ -150,8 +161,61 @@ func testConnection(t *testing.T, clientTpt, serverTpt *Transport) {
 
 	testIDs(t, clientTpt, serverTpt, clientConn, serverConn)
 	testKeys(t, clientTpt, serverTpt, clientConn, serverConn)
+	testConnProtocol(t, clientConn, serverConn)
 	testReadWrite(t, clientConn, serverConn)
 
 	clientConn.Close()
 	serverConn.Close()
 }
+
+func TestPeerVerifier(t *testing.T) {
+	clientTpt := newTestTransport(t, ci.Ed25519, 256)
+	serverTpt := newTestTransport(t, ci.Ed25519, 256)
+
+	errBlocked := errors.New("blocked")
+	var verified peer.ID
+	serverTpt.SetPeerVerifier(sec.PeerVerifierFunc(func(p peer.ID, pub ci.PubKey) error {
+		verified = p
+		if !p.MatchesPublicKey(pub) {
+			t.Error("peer ID doesn't match the public key")
+		}
+		return errBlocked
+	}))
+
+	client, server := newConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		clientTpt.SecureOutbound(context.TODO(), client, serverTpt.LocalPeer())
+	}()
+	_, err := serverTpt.SecureInbound(context.TODO(), server)
+	<-done
+
+	if !errors.Is(err, errBlocked) {
+		t.Fatalf("expected the handshake to be rejected, got %v", err)
+	}
+	if verified != clientTpt.LocalPeer() {
+		t.Fatalf("expected the client peer to be verified, got %s", verified)
+	}
+}
+
+func TestSetPeerVerifierConcurrently(t *testing.T) {
+	clientTpt := newTestTransport(t, ci.Ed25519, 256)
+	serverTpt := newTestTransport(t, ci.Ed25519, 256)
+	allow := sec.PeerVerifierFunc(func(peer.ID, ci.PubKey) error { return nil })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			serverTpt.SetPeerVerifier(allow)
+		}
+	}()
+	clientConn, serverConn := connect(t, clientTpt, serverTpt)
+	<-done
+	clientConn.Close()
+	serverConn.Close()
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"net"
 
+	ic "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 )
 
 // SecureConn is an authenticated, encrypted connection.
@@ -15,6 +17,25 @@ type SecureConn interface {
 	network.ConnSecurity
 }
 
+// ProtocolConn is an optional interface implemented by SecureConns that know
+// which security protocol was used to secure them.
+type ProtocolConn interface {
+	// ConnProtocol returns the protocol ID of the security transport that
+	// secured the connection, e.g. "/noise".
+	ConnProtocol() protocol.ID
+}
+
+// ConnProtocol returns the protocol ID of the security transport that
+// secured the connection, if the connection implements ProtocolConn, and ""
+// otherwise. It is mostly useful for logging and metrics, when several
+// security transports are enabled.
+func ConnProtocol(c SecureConn) protocol.ID {
+	if pc, ok := c.(ProtocolConn); ok {
+		return pc.ConnProtocol()
+	}
+	return ""
+}
+
 // A SecureTransport turns inbound and outbound unauthenticated,
 // plain-text, native connections into authenticated, encrypted connections.
 type SecureTransport interface {
@@ -25,6 +46,72 @@ type SecureTransport interface {
 	SecureOutbound(ctx context.Context, insecure net.Conn, p peer.ID) (SecureConn, error)
 }
 
+// EarlyDataTransport is an optional interface implemented by SecureTransports
+// that support sending application data during the handshake (0-RTT), to
+// save a round trip when talking to known peers. Callers should fall back to
+// SecureOutbound, followed by a regular write, for transports that don't
+// implement it.
+//
+// SECURITY: early data isn't protected against replay attacks. An attacker
+// recording the handshake can replay it, and the early data it contains, to
+// the server any number of times. Only send early data that is safe to
+// process multiple times (e.g. idempotent requests), and don't send anything
+// that must remain forward secret.
+type EarlyDataTransport interface {
+	SecureTransport
+
+	// SecureOutboundEarlyData secures an outbound connection, like
+	// SecureOutbound, sending earlyData to the remote peer during the
+	// handshake.
+	SecureOutboundEarlyData(ctx context.Context, insecure net.Conn, p peer.ID, earlyData []byte) (SecureConn, error)
+}
+
+// EarlyDataConn is implemented by the SecureConns returned by the
+// SecureInbound method of EarlyDataTransports.
+type EarlyDataConn interface {
+	SecureConn
+
+	// EarlyData returns the early data received during the handshake, or
+	// nil if there was none. See the security caveats on
+	// EarlyDataTransport: the data may have been replayed.
+	EarlyData() []byte
+}
+
+// PeerVerifier decides whether to proceed with a security handshake, given
+// the identity of the remote peer.
+type PeerVerifier interface {
+	// VerifyPeer is called with the remote peer's ID and public key, once
+	// they are known and authenticated. Returning an error aborts the
+	// handshake.
+	VerifyPeer(p peer.ID, pub ic.PubKey) error
+}
+
+// PeerVerifierFunc is a function implementing PeerVerifier.
+type PeerVerifierFunc func(p peer.ID, pub ic.PubKey) error
+
+// VerifyPeer calls f(p, pub).
+func (f PeerVerifierFunc) VerifyPeer(p peer.ID, pub ic.PubKey) error {
+	return f(p, pub)
+}
+
+// VerifyingTransport is an optional interface implemented by SecureTransports
+// that can consult a PeerVerifier during the handshake, e.g. to enforce an
+// allowlist.
+//
+// Unlike connmgr.ConnectionGater.InterceptSecured, which is called once the
+// handshake has completed, the PeerVerifier runs inside the security layer:
+// it is consulted as soon as the remote key is known, and rejecting the peer
+// aborts the handshake. A connection accepted by the PeerVerifier is still
+// subject to InterceptSecured afterwards.
+type VerifyingTransport interface {
+	SecureTransport
+
+	// SetPeerVerifier sets the PeerVerifier consulted during subsequent
+	// handshakes, replacing any previously set one. A nil PeerVerifier
+	// accepts all peers.
+	SetPeerVerifier(PeerVerifier)
+}
+
 // A SecureMuxer is a wrapper around SecureTransport which can select security protocols
 // and open outbound connections with simultaneous open.
 type SecureMuxer interface {
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/mux"
+)
+
+// SubtestHalfClose checks that a pair of connected streams implements the
+// half-close semantics documented on mux.MuxedStream: after CloseWrite,
+// writes fail, the remote side reads an EOF after the data written so far,
+// and the stream can still read until the remote side closes for writing.
+//
+// local and remote must be the two ends of the same, freshly opened stream.
+// Stream muxer implementations should run it as part of their test suite.
+// Both streams are closed when it returns.
+func SubtestHalfClose(t *testing.T, local, remote mux.MuxedStream) {
+	t.Helper()
+	defer local.Close()
+	defer remote.Close()
+
+	ping := []byte("ping")
+	pong := []byte("pong")
+
+	if _, err := local.Write(ping); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	if err := local.CloseWrite(); err != nil {
+		t.Fatalf("failed to close for writing: %s", err)
+	}
+	if _, err := local.Write(ping); err == nil {
+		t.Fatal("expected write after CloseWrite to fail")
+	}
+
+	// The remote must see the data, followed by an EOF.
+	got, err := ioutil.ReadAll(remote)
+	if err != nil {
+		t.Fatalf("failed to read from remote: %s", err)
+	}
+	if !bytes.Equal(got, ping) {
+		t.Fatalf("expected remote to read %q, got %q", ping, got)
+	}
+
+	// The other direction must still be open.
+	if _, err := remote.Write(pong); err != nil {
+		t.Fatalf("failed to write from remote: %s", err)
+	}
+	if err := remote.CloseWrite(); err != nil {
+		t.Fatalf("failed to close remote for writing: %s", err)
+	}
+	got, err = ioutil.ReadAll(local)
+	if err != nil {
+		t.Fatalf("failed to read after CloseWrite: %s", err)
+	}
+	if !bytes.Equal(got, pong) {
+		t.Fatalf("expected to read %q after CloseWrite, got %q", pong, got)
+	}
+}
@@ -0,0 +1,152 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// DefaultDialStagger is the default delay between the starts of consecutive
+// dials in DialMany, as recommended by RFC 8305 (Happy Eyeballs).
+const DefaultDialStagger = 250 * time.Millisecond
+
+// ErrNoAddrs is returned by DialMany when given no addresses to dial.
+var ErrNoAddrs = errors.New("no addresses to dial")
+
+// AddrDialError is the error of a dial to a single address.
+type AddrDialError struct {
+	Addr ma.Multiaddr
+	Err  error
+}
+
+func (e *AddrDialError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Addr, e.Err)
+}
+
+func (e *AddrDialError) Unwrap() error {
+	return e.Err
+}
+
+// DialErrors is returned by DialMany when all the dials failed. It holds
+// the error of each dial, in the order in which they failed.
+type DialErrors []*AddrDialError
+
+func (es DialErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("failed to dial %d addresses: %s", len(es), strings.Join(msgs, "; "))
+}
+
+type dialManyConfig struct {
+	stagger time.Duration
+}
+
+// DialManyOption is an option for DialMany.
+type DialManyOption func(*dialManyConfig)
+
+// WithDialStagger sets the delay between the starts of consecutive dials.
+// It defaults to DefaultDialStagger.
+func WithDialStagger(d time.Duration) DialManyOption {
+	return func(cfg *dialManyConfig) {
+		cfg.stagger = d
+	}
+}
+
+type dialResult struct {
+	conn CapableConn
+	addr ma.Multiaddr
+	err  error
+}
+
+// DialMany dials the given addresses of peer p with t, and returns the first
+// connection successfully established.
+//
+// Dials are raced with staggered starts (Happy Eyeballs): the addresses are
+// dialed in order, starting the next dial when the stagger delay elapses or
+// as soon as the previous dial fails. Once a dial succeeds, the remaining
+// dials are cancelled, and connections they established anyway are closed.
+//
+// If all the dials fail, the returned error is a DialErrors. If ctx is done
+// first, ctx.Err() is returned.
+func DialMany(ctx context.Context, t Transport, p peer.ID, addrs []ma.Multiaddr, opts ...DialManyOption) (CapableConn, error) {
+	if len(addrs) == 0 {
+		return nil, ErrNoAddrs
+	}
+	cfg := dialManyConfig{stagger: DefaultDialStagger}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, len(addrs))
+	var (
+		next    int
+		pending int
+		errs    DialErrors
+	)
+	startNext := func() {
+		addr := addrs[next]
+		next++
+		pending++
+		go func() {
+			c, err := t.Dial(ctx, addr, p)
+			results <- dialResult{conn: c, addr: addr, err: err}
+		}()
+	}
+
+	timer := time.NewTimer(cfg.stagger)
+	defer timer.Stop()
+	startNext()
+
+	for {
+		var timerC <-chan time.Time
+		if next < len(addrs) {
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			go closeLateConns(results, pending)
+			return nil, ctx.Err()
+		case <-timerC:
+			startNext()
+			timer.Reset(cfg.stagger)
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				go closeLateConns(results, pending)
+				return res.conn, nil
+			}
+			errs = append(errs, &AddrDialError{Addr: res.addr, Err: res.err})
+			if next < len(addrs) {
+				startNext()
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(cfg.stagger)
+			} else if pending == 0 {
+				return nil, errs
+			}
+		}
+	}
+}
+
+// closeLateConns closes the connections established by the pending dials
+// that succeed anyway after DialMany returned.
+func closeLateConns(results <-chan dialResult, pending int) {
+	for ; pending > 0; pending-- {
+		if r := <-results; r.err == nil {
+			r.conn.Close()
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Resolver is an optional interface implemented by transports that need to
+// resolve some of the addresses they can dial (e.g. /dnsaddr or /dns4
+// multiaddrs) into concrete addresses before dialing them.
+//
+// Resolution happens before dialing: the dialer calls Resolve (usually
+// through ResolveAll) on the addresses of the peer, and then dials the
+// resulting addresses.
+type Resolver interface {
+	// Resolve resolves maddr into concrete, dialable addresses. A single
+	// address may expand into multiple addresses. Addresses that don't need
+	// to be resolved are returned as is.
+	Resolve(ctx context.Context, maddr ma.Multiaddr) ([]ma.Multiaddr, error)
+}
+
+// ResolveAll resolves addrs using t, if t implements Resolver. Otherwise,
+// addrs is returned unchanged.
+//
+// The resolved addresses are returned in the order of the input addresses
+// they were resolved from. If resolving any of the addresses fails,
+// ResolveAll returns an error.
+func ResolveAll(ctx context.Context, t Transport, addrs []ma.Multiaddr) ([]ma.Multiaddr, error) {
+	r, ok := t.(Resolver)
+	if !ok {
+		return addrs, nil
+	}
+	resolved := make([]ma.Multiaddr, 0, len(addrs))
+	for _, a := range addrs {
+		as, err := r.Resolve(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", a, err)
+		}
+		resolved = append(resolved, as...)
+	}
+	return resolved, nil
+}
@@ -115,3 +115,67 @@ type TransportNetwork interface {
 	// handle the last protocol in the multiaddr.
 	AddTransport(t Transport) error
 }
+
+// ListenAddrChanger is an optional interface implemented by Listeners whose
+// listen address can change while they're running, e.g. because of a NAT
+// remapping or a QUIC connection migration.
+//
+// Upgraders wrapping such listeners must implement it as well, forwarding
+// the changes of the underlying listener. The Network consumes it (see
+// ListenAddrChanges) to update its listen addresses, so that the host can
+// promptly emit an event.EvtLocalAddressesUpdated.
+type ListenAddrChanger interface {
+	// ListenAddrChanged returns a channel on which the new listen address
+	// is sent whenever it changes. The channel is closed when the listener
+	// is closed. After a change, Multiaddr returns the new address.
+	ListenAddrChanged() <-chan ma.Multiaddr
+}
+
+// ListenAddrChanges returns the channel of listen address changes of l, if
+// it implements ListenAddrChanger, and nil otherwise. As receiving from a
+// nil channel blocks forever, the result can be used in a select statement
+// regardless of whether l supports address changes.
+func ListenAddrChanges(l Listener) <-chan ma.Multiaddr {
+	if c, ok := l.(ListenAddrChanger); ok {
+		return c.ListenAddrChanged()
+	}
+	return nil
+}
+
+// UpgradeStats holds the timings of the setup phases of an upgraded
+// connection. A zero duration means that the phase didn't take place, e.g.
+// because the transport natively provides security and stream multiplexing.
+type UpgradeStats struct {
+	// SecureHandshakeDuration is the time spent negotiating the security
+	// protocol and running its handshake.
+	SecureHandshakeDuration time.Duration
+	// MuxNegotiationDuration is the time spent negotiating the stream
+	// multiplexer and setting it up.
+	MuxNegotiationDuration time.Duration
+}
+
+// UpgradeStatsConn is an optional interface implemented by CapableConns
+// which record the timings of their connection upgrade.
+type UpgradeStatsConn interface {
+	// UpgradeStats returns the timings of the connection upgrade.
+	UpgradeStats() UpgradeStats
+}
+
+// ConnUpgradeStats returns the upgrade timings of c, and whether c records
+// them (see UpgradeStatsConn).
+func ConnUpgradeStats(c CapableConn) (UpgradeStats, bool) {
+	if sc, ok := c.(UpgradeStatsConn); ok {
+		return sc.UpgradeStats(), true
+	}
+	return UpgradeStats{}, false
+}
+
+// ConnScopeOf returns the resource scope c is accounted to, or
+// network.NullScope if c doesn't implement network.ScopedConn. Upgraders
+// should implement network.ScopedConn on the connections they return.
+func ConnScopeOf(c CapableConn) network.ConnScope {
+	if sc, ok := c.(network.ScopedConn); ok {
+		return sc.Scope()
+	}
+	return network.NullScope
+}
@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+type stubTransport struct{}
+
+func (stubTransport) Dial(context.Context, ma.Multiaddr, peer.ID) (CapableConn, error) {
+	return nil, errors.New("not implemented")
+}
+func (stubTransport) CanDial(ma.Multiaddr) bool { return true }
+func (stubTransport) Listen(ma.Multiaddr) (Listener, error) {
+	return nil, errors.New("not implemented")
+}
+func (stubTransport) Protocols() []int { return []int{ma.P_TCP} }
+func (stubTransport) Proxy() bool      { return false }
+
+var errUnresolvable = errors.New("unresolvable")
+
+// resolvingTransport resolves /dns4/example.com to two addresses.
+type resolvingTransport struct{ stubTransport }
+
+func (resolvingTransport) Resolve(ctx context.Context, maddr ma.Multiaddr) ([]ma.Multiaddr, error) {
+	host, err := maddr.ValueForProtocol(ma.P_DNS4)
+	if err != nil {
+		return []ma.Multiaddr{maddr}, nil
+	}
+	if host != "example.com" {
+		return nil, errUnresolvable
+	}
+	return []ma.Multiaddr{
+		ma.StringCast("/ip4/1.2.3.4/tcp/1"),
+		ma.StringCast("/ip4/5.6.7.8/tcp/1"),
+	}, nil
+}
+
+func TestResolveAll(t *testing.T) {
+	ctx := context.Background()
+	addrs := []ma.Multiaddr{
+		ma.StringCast("/dns4/example.com/tcp/1"),
+		ma.StringCast("/ip4/9.9.9.9/tcp/1"),
+	}
+
+	res, err := ResolveAll(ctx, stubTransport{}, addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || !res[0].Equal(addrs[0]) || !res[1].Equal(addrs[1]) {
+		t.Fatalf("expected addresses to be passed through, got %v", res)
+	}
+
+	res, err = ResolveAll(ctx, resolvingTransport{}, addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/ip4/1.2.3.4/tcp/1", "/ip4/5.6.7.8/tcp/1", "/ip4/9.9.9.9/tcp/1"}
+	if len(res) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, res)
+	}
+	for i, a := range res {
+		if a.String() != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, res)
+		}
+	}
+
+	_, err = ResolveAll(ctx, resolvingTransport{}, []ma.Multiaddr{ma.StringCast("/dns4/example.org/tcp/1")})
+	if !errors.Is(err, errUnresolvable) {
+		t.Fatalf("expected the resolution error, got %v", err)
+	}
+}
+
+type stubListener struct {
+	addr    ma.Multiaddr
+	changes chan ma.Multiaddr
+}
+
+func (l *stubListener) Accept() (CapableConn, error) { return nil, errors.New("not implemented") }
+func (l *stubListener) Close() error                 { close(l.changes); return nil }
+func (l *stubListener) Addr() net.Addr               { return nil }
+func (l *stubListener) Multiaddr() ma.Multiaddr      { return l.addr }
+
+type changingListener struct{ *stubListener }
+
+func (l changingListener) ListenAddrChanged() <-chan ma.Multiaddr { return l.changes }
+
+func (l changingListener) migrate(addr ma.Multiaddr) {
+	l.addr = addr
+	l.changes <- addr
+}
+
+func TestListenAddrChanges(t *testing.T) {
+	if ch := ListenAddrChanges(&stubListener{}); ch != nil {
+		t.Fatal("expected a nil channel for listeners that don't support address changes")
+	}
+
+	l := changingListener{&stubListener{
+		addr:    ma.StringCast("/ip4/1.2.3.4/udp/1/quic"),
+		changes: make(chan ma.Multiaddr, 1),
+	}}
+
+	// Propagate changes like a network would.
+	var listenAddrs []ma.Multiaddr
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for a := range ListenAddrChanges(l) {
+			listenAddrs = append(listenAddrs, a)
+		}
+	}()
+
+	migrated := ma.StringCast("/ip4/1.2.3.4/udp/2/quic")
+	l.migrate(migrated)
+	l.Close()
+	<-done
+
+	if len(listenAddrs) != 1 || !listenAddrs[0].Equal(migrated) {
+		t.Fatalf("expected the change to %s to be propagated, got %v", migrated, listenAddrs)
+	}
+	if !l.Multiaddr().Equal(migrated) {
+		t.Fatalf("expected the listener address to be %s, got %s", migrated, l.Multiaddr())
+	}
+}
+
+type stubConn struct {
+	CapableConn
+	closed int32
+}
+
+func (c *stubConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return nil
+}
+
+// dialBehavior describes how dialingTransport dials an address: after delay,
+// it fails with err if set, and succeeds otherwise.
+type dialBehavior struct {
+	delay time.Duration
+	err   error
+}
+
+type dialingTransport struct {
+	stubTransport
+	behaviors map[string]dialBehavior
+	conns     chan *stubConn
+}
+
+func (t *dialingTransport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (CapableConn, error) {
+	b := t.behaviors[raddr.String()]
+	if b.delay > 0 {
+		time.Sleep(b.delay)
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	c := &stubConn{}
+	t.conns <- c
+	return c, nil
+}
+
+func TestDialMany(t *testing.T) {
+	ctx := context.Background()
+	slow := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	fast := ma.StringCast("/ip4/5.6.7.8/tcp/1")
+	broken := ma.StringCast("/ip4/9.9.9.9/tcp/1")
+	errBroken := errors.New("connection refused")
+
+	tpt := &dialingTransport{
+		behaviors: map[string]dialBehavior{
+			slow.String():   {delay: 200 * time.Millisecond},
+			fast.String():   {},
+			broken.String(): {err: errBroken},
+		},
+		conns: make(chan *stubConn, 3),
+	}
+
+	if _, err := DialMany(ctx, tpt, "", nil); err != ErrNoAddrs {
+		t.Fatalf("expected ErrNoAddrs, got %v", err)
+	}
+
+	// The second dial starts after the stagger delay, and wins the race.
+	c, err := DialMany(ctx, tpt, "", []ma.Multiaddr{slow, fast}, WithDialStagger(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	winner := <-tpt.conns
+	if c != winner {
+		t.Fatal("expected the connection of the fastest dial")
+	}
+	// The connection of the slow dial is closed once established.
+	late := <-tpt.conns
+	for i := 0; atomic.LoadInt32(&late.closed) == 0; i++ {
+		if i > 100 {
+			t.Fatal("expected the late connection to be closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&winner.closed) != 0 {
+		t.Fatal("expected the returned connection to remain open")
+	}
+
+	// A failed dial starts the next one without waiting for the stagger delay.
+	start := time.Now()
+	if _, err := DialMany(ctx, tpt, "", []ma.Multiaddr{broken, fast}, WithDialStagger(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	<-tpt.conns
+	if time.Since(start) > time.Minute {
+		t.Fatal("expected the next dial to start right after the failure")
+	}
+
+	_, err = DialMany(ctx, tpt, "", []ma.Multiaddr{broken, broken})
+	errs, ok := err.(DialErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected the errors of both dials, got %v", err)
+	}
+	if !errs[0].Addr.Equal(broken) || !errors.Is(errs[0], errBroken) {
+		t.Fatalf("unexpected dial error %v", errs[0])
+	}
+}
+
+type timedConn struct {
+	*stubConn
+	stats UpgradeStats
+}
+
+func (c timedConn) UpgradeStats() UpgradeStats { return c.stats }
+
+func TestConnUpgradeStats(t *testing.T) {
+	if _, ok := ConnUpgradeStats(&stubConn{}); ok {
+		t.Fatal("expected no upgrade stats for connections that don't record them")
+	}
+
+	expected := UpgradeStats{
+		SecureHandshakeDuration: 30 * time.Millisecond,
+		MuxNegotiationDuration:  5 * time.Millisecond,
+	}
+	stats, ok := ConnUpgradeStats(timedConn{&stubConn{}, expected})
+	if !ok || stats != expected {
+		t.Fatalf("expected %+v, got %+v", expected, stats)
+	}
+}